@@ -0,0 +1,195 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"warnnotice/database"
+	"warnnotice/pkg/e"
+	"warnnotice/scheduler"
+	"warnnotice/util"
+)
+
+// GetChecks 获取健康检查配置列表及最近一次检查结果
+func GetChecks(c *gin.Context) {
+	checks, err := database.GetChecks(false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  "获取健康检查配置失败: " + err.Error(),
+		})
+		return
+	}
+
+	latest, err := database.GetLatestCheckResults()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  "获取健康检查结果失败: " + err.Error(),
+		})
+		return
+	}
+
+	data := make([]gin.H, 0, len(checks))
+	for _, check := range checks {
+		item := gin.H{"check": check, "latest": nil}
+		if result, exists := latest[check.ID]; exists {
+			item["latest"] = result
+		}
+		data = append(data, item)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "获取健康检查配置成功",
+		"data": data,
+	})
+}
+
+// SetCheck 新增或更新健康检查配置
+func SetCheck(c *gin.Context) {
+	check, ok := bindCheck(c)
+	if !ok {
+		return
+	}
+
+	id, err := database.SaveCheck(check)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  "保存健康检查配置失败: " + err.Error(),
+		})
+		return
+	}
+
+	// 重启检查任务以应用新配置
+	scheduler.RestartCheckScheduler()
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "健康检查配置保存成功",
+		"data": gin.H{
+			"id": id,
+		},
+	})
+}
+
+// DeleteCheck 删除健康检查配置及其检查历史
+func DeleteCheck(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	if err = database.DeleteCheck(id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  "删除健康检查配置失败: " + err.Error(),
+		})
+		return
+	}
+
+	scheduler.RestartCheckScheduler()
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "健康检查配置删除成功",
+	})
+}
+
+// GetCheckHistory 获取健康检查历史
+func GetCheckHistory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit <= 0 {
+		limit = 100
+	}
+
+	history, err := database.GetCheckHistory(id, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  "获取健康检查历史失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "获取健康检查历史成功",
+		"data": history,
+	})
+}
+
+// GetCertificates 获取所有证书检查记录的证书及剩余有效期
+func GetCertificates(c *gin.Context) {
+	certs, err := database.GetCertificateStatuses()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  "获取证书信息失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "获取证书信息成功",
+		"data": certs,
+	})
+}
+
+// TestCheck 立即执行一次健康检查，不保存结果也不告警
+func TestCheck(c *gin.Context) {
+	check, ok := bindCheck(c)
+	if !ok {
+		return
+	}
+
+	result := util.RunCheck(c.Request.Context(), check)
+
+	msg := "检查成功"
+	if !result.Success {
+		msg = "检查失败: " + result.Message
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  msg,
+		"data": result,
+	})
+}
+
+// bindCheck 解析并校验健康检查配置，失败时直接返回错误响应
+func bindCheck(c *gin.Context) (util.Check, bool) {
+	// 未提供的字段使用默认值
+	check := util.DefaultCheck()
+	if err := c.ShouldBindJSON(&check); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "参数错误: " + err.Error(),
+		})
+		return check, false
+	}
+
+	if err := check.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "参数错误: " + err.Error(),
+		})
+		return check, false
+	}
+
+	return check, true
+}
@@ -0,0 +1,219 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/huobirdcenter/huobi_golang/logging/applogger"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+	"warnnotice/database"
+	"warnnotice/pkg/e"
+	"warnnotice/scheduler"
+	"warnnotice/util"
+)
+
+// heartbeatMaxBody ping请求内容的最大长度
+const heartbeatMaxBody = 10 << 10
+
+// GetHeartbeats 获取心跳监控配置列表及当前状态
+func GetHeartbeats(c *gin.Context) {
+	heartbeats, err := database.GetHeartbeats(false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  "获取心跳监控配置失败: " + err.Error(),
+		})
+		return
+	}
+
+	now := time.Now()
+	data := make([]gin.H, 0, len(heartbeats))
+	for _, h := range heartbeats {
+		data = append(data, gin.H{
+			"heartbeat": h,
+			"status":    h.Status(now),
+			"ping_url":  "/api/v1/heartbeat/" + h.Token,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "获取心跳监控配置成功",
+		"data": data,
+	})
+}
+
+// SetHeartbeat 新增或更新心跳监控配置
+func SetHeartbeat(c *gin.Context) {
+	// 未提供的字段使用默认值
+	heartbeat := util.DefaultHeartbeat()
+	if err := c.ShouldBindJSON(&heartbeat); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	if err := heartbeat.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	if heartbeat.ID == 0 {
+		token, err := util.NewHeartbeatToken()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code": e.ERROR,
+				"msg":  err.Error(),
+			})
+			return
+		}
+		heartbeat.Token = token
+	}
+
+	id, err := database.SaveHeartbeat(heartbeat)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  "保存心跳监控配置失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "心跳监控配置保存成功",
+		"data": gin.H{
+			"id": id,
+		},
+	})
+}
+
+// DeleteHeartbeat 删除心跳监控配置及其ping记录
+func DeleteHeartbeat(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	if err = database.DeleteHeartbeat(id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  "删除心跳监控配置失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "心跳监控配置删除成功",
+	})
+}
+
+// GetHeartbeatPings 获取心跳ping记录
+func GetHeartbeatPings(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit <= 0 {
+		limit = 100
+	}
+
+	pings, err := database.GetHeartbeatPings(id, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  "获取心跳ping记录失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "获取心跳ping记录成功",
+		"data": pings,
+	})
+}
+
+// PingHeartbeat 外部任务请求的ping地址，路径后缀start/success/fail表示任务开始、成功和失败，请求内容作为任务日志保存
+func PingHeartbeat(c *gin.Context) {
+	kind := c.Param("kind")
+	if kind == "" {
+		kind = util.HeartbeatPingSuccess
+	}
+	if !util.ValidHeartbeatPingKind(kind) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "ping类型错误: " + kind,
+		})
+		return
+	}
+
+	heartbeat, err := database.GetHeartbeatByToken(c.Param("token"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  "获取心跳监控配置失败: " + err.Error(),
+		})
+		return
+	}
+	if heartbeat == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "心跳不存在",
+		})
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, heartbeatMaxBody))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "读取请求内容失败: " + err.Error(),
+		})
+		return
+	}
+
+	ping := util.HeartbeatPing{
+		HeartbeatID: heartbeat.ID,
+		Kind:        kind,
+		Body:        string(body),
+		RemoteAddr:  c.ClientIP(),
+		Timestamp:   time.Now().Unix(),
+	}
+	if err = database.SaveHeartbeatPing(ping); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  "保存心跳ping记录失败: " + err.Error(),
+		})
+		return
+	}
+
+	if heartbeat.Enabled {
+		heartbeat.LastPingAt, heartbeat.LastPingKind = ping.Timestamp, ping.Kind
+		if err = scheduler.CheckHeartbeat(*heartbeat); err != nil {
+			applogger.Error("心跳%s告警失败: %v", heartbeat.Name, err)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "OK",
+	})
+}
@@ -0,0 +1,270 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"strings"
+	"warnnotice/database"
+	"warnnotice/middleware/apikey"
+	"warnnotice/notifier"
+	"warnnotice/pkg/e"
+	"warnnotice/util"
+)
+
+// CreateAlert 接收外部系统推送的告警
+func CreateAlert(c *gin.Context) {
+	var alert util.InboundAlert
+	if err := c.ShouldBindJSON(&alert); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	source, ok := inboundSource(c, alert.Source)
+	if !ok {
+		return
+	}
+	alert.Source = source
+	if err := alert.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	notified, err := notifier.Receive(alert)
+	data := gin.H{
+		"dedup_key": alert.DedupKey,
+		"notified":  notified, // 重复通知间隔内的相同告警不再发送
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  err.Error(),
+			"data": data,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "告警接收成功",
+		"data": data,
+	})
+}
+
+// ResolveAlert 按去重标识恢复外部系统推送的告警
+func ResolveAlert(c *gin.Context) {
+	var req struct {
+		Source   string `json:"source"`
+		DedupKey string `json:"dedup_key" binding:"required"`
+		Message  string `json:"message"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	source, ok := inboundSource(c, req.Source)
+	if !ok {
+		return
+	}
+
+	resolved, err := notifier.ResolveInbound(source, req.DedupKey, req.Message)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  "恢复告警失败: " + err.Error(),
+		})
+		return
+	}
+	if !resolved {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "没有未恢复的告警: " + req.DedupKey,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "告警已恢复",
+	})
+}
+
+// ReceiveAlertmanager 接收Prometheus Alertmanager的Webhook，逐条转换为告警或恢复消息
+func ReceiveAlertmanager(c *gin.Context) {
+	var payload util.AlertmanagerPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "参数错误: " + err.Error(),
+		})
+		return
+	}
+	if err := payload.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	source := c.GetString(apikey.SOURCE)
+	var fired, notified, resolved int
+	errs := make([]string, 0)
+	for _, a := range payload.Alerts {
+		inbound := a.Inbound(source)
+		if a.Status == util.AlertStatusResolved {
+			ok, err := notifier.ResolveInbound(source, inbound.DedupKey, a.ResolvedMessage())
+			if err != nil {
+				errs = append(errs, err.Error())
+			}
+			if ok {
+				resolved++
+			}
+			continue
+		}
+
+		fired++
+		sent, err := notifier.Receive(inbound)
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+		if sent {
+			notified++
+		}
+	}
+
+	data := gin.H{
+		"firing":   fired,
+		"notified": notified,
+		"resolved": resolved,
+	}
+	// 返回错误时Alertmanager会重试，重复的告警按去重标识归并
+	if len(errs) > 0 {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  strings.Join(errs, "; "),
+			"data": data,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "告警接收成功",
+		"data": data,
+	})
+}
+
+// inboundSource 获取API密钥对应的告警来源，请求中指定的来源与密钥不一致时直接返回错误响应
+func inboundSource(c *gin.Context, requested string) (string, bool) {
+	source := c.GetString(apikey.SOURCE)
+	if requested != "" && requested != source {
+		c.JSON(http.StatusForbidden, gin.H{
+			"code": e.ERROR_AUTH,
+			"msg":  "API密钥无权推送来源为" + requested + "的告警",
+		})
+		return "", false
+	}
+	return source, true
+}
+
+// GetAPIKeys 获取外部告警API密钥列表
+func GetAPIKeys(c *gin.Context) {
+	keys, err := database.GetAPIKeys()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  "获取API密钥失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "获取API密钥成功",
+		"data": keys,
+	})
+}
+
+// CreateAPIKey 为告警来源生成API密钥，密钥只在创建时返回一次
+func CreateAPIKey(c *gin.Context) {
+	key := util.APIKey{Enabled: true}
+	if err := c.ShouldBindJSON(&key); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	if err := key.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	secret, err := util.NewAPIKeySecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  err.Error(),
+		})
+		return
+	}
+	key.Prefix = secret[:10]
+
+	id, err := database.CreateAPIKey(key, util.HashAPIKey(secret))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  "保存API密钥失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "API密钥创建成功，请妥善保存，密钥不会再次显示",
+		"data": gin.H{
+			"id":  id,
+			"key": secret,
+		},
+	})
+}
+
+// DeleteAPIKey 删除外部告警API密钥
+func DeleteAPIKey(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	if err = database.DeleteAPIKey(id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  "删除API密钥失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "API密钥删除成功",
+	})
+}
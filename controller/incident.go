@@ -0,0 +1,209 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"warnnotice/database"
+	"warnnotice/notifier"
+	"warnnotice/pkg/e"
+)
+
+// GetIncidents 获取告警事件列表，可按状态过滤
+func GetIncidents(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 {
+		limit = 50
+	}
+
+	incidents, err := database.GetIncidents(c.Query("status"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  "获取告警事件失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "获取告警事件成功",
+		"data": incidents,
+	})
+}
+
+// GetIncident 获取告警事件详情，包括关联的发送记录、备注和进程快照
+func GetIncident(c *gin.Context) {
+	incident, ok := loadIncident(c)
+	if !ok {
+		return
+	}
+
+	alerts, err := database.GetIncidentAlertHistory(incident.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  "获取告警事件发送记录失败: " + err.Error(),
+		})
+		return
+	}
+
+	notes, err := database.GetIncidentNotes(incident.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  "获取告警事件备注失败: " + err.Error(),
+		})
+		return
+	}
+
+	processes, err := database.GetIncidentProcesses(incident.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  "获取告警事件进程快照失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "获取告警事件成功",
+		"data": gin.H{
+			"incident":  incident,
+			"alerts":    alerts,
+			"notes":     notes,
+			"processes": processes,
+		},
+	})
+}
+
+// incidentActionRequest 告警事件操作请求
+type incidentActionRequest struct {
+	User    string `json:"user"`    // 操作人
+	Content string `json:"content"` // 备注内容
+}
+
+// AcknowledgeIncident 确认告警事件，确认后在恢复前不再重复通知
+func AcknowledgeIncident(c *gin.Context) {
+	updateIncident(c, "确认", database.AcknowledgeIncident)
+}
+
+// ResolveIncident 手动关闭告警事件，告警条件仍然存在时重新告警
+func ResolveIncident(c *gin.Context) {
+	updateIncident(c, "关闭", notifier.CloseIncident)
+}
+
+// AddIncidentNote 添加告警事件备注
+func AddIncidentNote(c *gin.Context) {
+	incident, ok := loadIncident(c)
+	if !ok {
+		return
+	}
+
+	var req incidentActionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "参数错误: " + err.Error(),
+		})
+		return
+	}
+	if req.User == "" || req.Content == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "操作人和备注内容不能为空",
+		})
+		return
+	}
+
+	if err := database.AddIncidentNote(incident.ID, req.User, req.Content); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  "添加告警事件备注失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "添加告警事件备注成功",
+	})
+}
+
+// updateIncident 更新告警事件状态，备注内容不为空时同时记录备注
+func updateIncident(c *gin.Context, action string, update func(id int, user string) error) {
+	incident, ok := loadIncident(c)
+	if !ok {
+		return
+	}
+
+	var req incidentActionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "参数错误: " + err.Error(),
+		})
+		return
+	}
+	if req.User == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "操作人不能为空",
+		})
+		return
+	}
+
+	if err := update(incident.ID, req.User); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.ERROR,
+			"msg":  action + "告警事件失败: " + err.Error(),
+		})
+		return
+	}
+
+	if req.Content != "" {
+		if err := database.AddIncidentNote(incident.ID, req.User, req.Content); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code": e.ERROR,
+				"msg":  "添加告警事件备注失败: " + err.Error(),
+			})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  action + "告警事件成功",
+	})
+}
+
+// loadIncident 根据路径参数加载告警事件，失败时直接返回错误响应
+func loadIncident(c *gin.Context) (*database.Incident, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "参数错误: " + err.Error(),
+		})
+		return nil, false
+	}
+
+	incident, err := database.GetIncident(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  "获取告警事件失败: " + err.Error(),
+		})
+		return nil, false
+	}
+	if incident == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "告警事件不存在",
+		})
+		return nil, false
+	}
+
+	return incident, true
+}
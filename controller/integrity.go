@@ -0,0 +1,178 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"warnnotice/database"
+	"warnnotice/pkg/e"
+	"warnnotice/scheduler"
+	"warnnotice/util"
+)
+
+// GetIntegrityWatchers 获取文件完整性监控配置列表
+func GetIntegrityWatchers(c *gin.Context) {
+	watchers, err := database.GetIntegrityWatchers(false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  "获取文件完整性监控配置失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "获取文件完整性监控配置成功",
+		"data": watchers,
+	})
+}
+
+// SetIntegrityWatcher 新增或更新文件完整性监控配置
+func SetIntegrityWatcher(c *gin.Context) {
+	// 未提供的字段使用默认值
+	watcher := util.DefaultIntegrityWatcher()
+	if err := c.ShouldBindJSON(&watcher); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	if err := watcher.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	id, err := database.SaveIntegrityWatcher(watcher)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  "保存文件完整性监控配置失败: " + err.Error(),
+		})
+		return
+	}
+
+	// 重启扫描任务以应用新配置，新增的监控在首次扫描时建立基线
+	scheduler.RestartIntegrityScheduler()
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "文件完整性监控配置保存成功",
+		"data": gin.H{
+			"id": id,
+		},
+	})
+}
+
+// DeleteIntegrityWatcher 删除文件完整性监控配置
+func DeleteIntegrityWatcher(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	if err = database.DeleteIntegrityWatcher(id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  "删除文件完整性监控配置失败: " + err.Error(),
+		})
+		return
+	}
+
+	scheduler.RestartIntegrityScheduler()
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "文件完整性监控配置删除成功",
+	})
+}
+
+// GetIntegrityDiff 立即扫描文件，返回与基线的差异
+func GetIntegrityDiff(c *gin.Context) {
+	watcher, ok := loadIntegrityWatcher(c)
+	if !ok {
+		return
+	}
+	if watcher.BaselineAt == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "尚未建立基线",
+		})
+		return
+	}
+
+	diff, err := scheduler.IntegrityDiff(*watcher)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  "扫描文件失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "扫描文件成功",
+		"data": diff,
+	})
+}
+
+// ApproveIntegrityBaseline 预期内的变更完成后，用当前文件状态重新确认基线
+func ApproveIntegrityBaseline(c *gin.Context) {
+	watcher, ok := loadIntegrityWatcher(c)
+	if !ok {
+		return
+	}
+
+	if err := scheduler.ApproveIntegrity(*watcher); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  "确认基线失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "基线已重新确认",
+	})
+}
+
+// loadIntegrityWatcher 根据路径参数加载文件完整性监控配置，失败时直接返回错误响应
+func loadIntegrityWatcher(c *gin.Context) (*util.IntegrityWatcher, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "参数错误: " + err.Error(),
+		})
+		return nil, false
+	}
+
+	watcher, err := database.GetIntegrityWatcher(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  "获取文件完整性监控配置失败: " + err.Error(),
+		})
+		return nil, false
+	}
+	if watcher == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "文件完整性监控配置不存在",
+		})
+		return nil, false
+	}
+
+	return watcher, true
+}
@@ -0,0 +1,141 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"warnnotice/database"
+	"warnnotice/pkg/e"
+	"warnnotice/scheduler"
+	"warnnotice/util"
+)
+
+// logTestLines 测试日志匹配规则时读取的行数
+const logTestLines = 1000
+
+// GetLogWatchers 获取日志监控配置列表
+func GetLogWatchers(c *gin.Context) {
+	watchers, err := database.GetLogWatchers(false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  "获取日志监控配置失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "获取日志监控配置成功",
+		"data": watchers,
+	})
+}
+
+// SetLogWatcher 新增或更新日志监控配置
+func SetLogWatcher(c *gin.Context) {
+	watcher, ok := bindLogWatcher(c)
+	if !ok {
+		return
+	}
+
+	id, err := database.SaveLogWatcher(watcher)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  "保存日志监控配置失败: " + err.Error(),
+		})
+		return
+	}
+
+	// 重启日志监控任务以应用新配置
+	scheduler.RestartLogScheduler()
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "日志监控配置保存成功",
+		"data": gin.H{
+			"id": id,
+		},
+	})
+}
+
+// DeleteLogWatcher 删除日志监控配置
+func DeleteLogWatcher(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	if err = database.DeleteLogWatcher(id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  "删除日志监控配置失败: " + err.Error(),
+		})
+		return
+	}
+
+	scheduler.RestartLogScheduler()
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "日志监控配置删除成功",
+	})
+}
+
+// TestLogWatcher 用日志文件末尾的内容测试匹配规则，返回各规则匹配的行
+func TestLogWatcher(c *gin.Context) {
+	watcher, ok := bindLogWatcher(c)
+	if !ok {
+		return
+	}
+
+	lines, err := util.ReadLastLines(watcher.Path, logTestLines)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  err.Error(),
+		})
+		return
+	}
+
+	matches, err := util.MatchLogLines(watcher, lines)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "已匹配日志文件最后" + strconv.Itoa(len(lines)) + "行",
+		"data": matches,
+	})
+}
+
+// bindLogWatcher 解析并校验日志监控配置，失败时直接返回错误响应
+func bindLogWatcher(c *gin.Context) (util.LogWatcher, bool) {
+	watcher := util.LogWatcher{Enabled: true}
+	if err := c.ShouldBindJSON(&watcher); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "参数错误: " + err.Error(),
+		})
+		return watcher, false
+	}
+
+	if err := watcher.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "参数错误: " + err.Error(),
+		})
+		return watcher, false
+	}
+
+	return watcher, true
+}
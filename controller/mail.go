@@ -19,6 +19,14 @@ func SetEmailConfig(c *gin.Context) {
 		return
 	}
 
+	if config.MinSeverity != "" && !util.ValidSeverity(config.MinSeverity) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "告警级别错误: " + config.MinSeverity,
+		})
+		return
+	}
+
 	// 保存到数据库
 	err := database.SaveEmailConfig(config)
 	if err != nil {
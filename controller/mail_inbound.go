@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"warnnotice/database"
+	"warnnotice/pkg/e"
+	"warnnotice/scheduler"
+	"warnnotice/util"
+)
+
+// GetMailInboundConfig 获取邮件接收配置
+func GetMailInboundConfig(c *gin.Context) {
+	config := util.DefaultMailInboundConfig()
+	if e.MailInboundConfig != nil {
+		config = *e.MailInboundConfig
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "获取邮件接收配置成功",
+		"data": config,
+	})
+}
+
+// SetMailInboundConfig 保存邮件接收配置并重启SMTP服务
+func SetMailInboundConfig(c *gin.Context) {
+	// 以当前配置为基础，请求中未提供的字段保持不变
+	config := util.DefaultMailInboundConfig()
+	if e.MailInboundConfig != nil {
+		config = *e.MailInboundConfig
+	}
+	if err := c.ShouldBindJSON(&config); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	if err := config.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	if err := database.SaveMailInboundConfig(config); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  "保存邮件接收配置失败: " + err.Error(),
+		})
+		return
+	}
+
+	e.MailInboundConfig = &config
+	scheduler.RestartMailInbound()
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "邮件接收配置保存成功",
+	})
+}
@@ -12,7 +12,11 @@ import (
 
 // 监控配置处理函数
 func SetMonitorConfig(c *gin.Context) {
-	var config util.MonitorConfig
+	// 以当前配置为基础，请求中未提供的字段保持不变
+	config := util.DefaultMonitorConfig()
+	if e.MonitorConfig != nil {
+		config = *e.MonitorConfig
+	}
 	if err := c.ShouldBindJSON(&config); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"code": e.INVALID_PARAMS,
@@ -21,6 +25,14 @@ func SetMonitorConfig(c *gin.Context) {
 		return
 	}
 
+	if err := config.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "参数错误: " + err.Error(),
+		})
+		return
+	}
+
 	// 保存到数据库
 	err := database.SaveMonitorConfig(config)
 	if err != nil {
@@ -62,7 +74,11 @@ func GetMonitorConfig(c *gin.Context) {
 	})
 }
 func GetSystemStatus(c *gin.Context) {
-	status, err := util.GetSystemStatus()
+	filter := util.DefaultDiskFilter()
+	if e.MonitorConfig != nil {
+		filter = e.MonitorConfig.DiskFilter
+	}
+	status, err := util.GetSystemStatus(filter, nil)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code": e.ERROR,
@@ -0,0 +1,190 @@
+package controller
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"time"
+	"warnnotice/database"
+	"warnnotice/notifier"
+	"warnnotice/pkg/e"
+	"warnnotice/util"
+)
+
+// GetNotifyChannels 获取通知渠道列表
+func GetNotifyChannels(c *gin.Context) {
+	channels, err := database.GetNotifyChannels()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  "获取通知渠道失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "获取通知渠道成功",
+		"data": gin.H{
+			"channels": channels,
+			"types":    notifier.Types(),
+		},
+	})
+}
+
+// SetNotifyChannel 新增或更新通知渠道
+func SetNotifyChannel(c *gin.Context) {
+	// 未指定enabled时默认启用
+	channel := database.NotifyChannel{Enabled: true}
+	if err := c.ShouldBindJSON(&channel); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	if channel.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "渠道名称不能为空",
+		})
+		return
+	}
+
+	if channel.MinSeverity != "" && !util.ValidSeverity(channel.MinSeverity) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "告警级别错误: " + channel.MinSeverity,
+		})
+		return
+	}
+
+	// 校验渠道配置
+	if _, err := notifier.Build(channel); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "渠道配置错误: " + err.Error(),
+		})
+		return
+	}
+
+	// 保存到数据库
+	id, err := database.SaveNotifyChannel(channel)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  "保存通知渠道失败: " + err.Error(),
+		})
+		return
+	}
+
+	// 重新加载通知渠道
+	if err = notifier.Reload(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  "加载通知渠道失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "通知渠道保存成功",
+		"data": gin.H{
+			"id": id,
+		},
+	})
+}
+
+// DeleteNotifyChannel 删除通知渠道
+func DeleteNotifyChannel(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	if err = database.DeleteNotifyChannel(id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  "删除通知渠道失败: " + err.Error(),
+		})
+		return
+	}
+
+	if err = notifier.Reload(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  "加载通知渠道失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "通知渠道删除成功",
+	})
+}
+
+// TestNotifyChannel 通过指定渠道发送测试消息
+func TestNotifyChannel(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	channel, err := database.GetNotifyChannel(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  "获取通知渠道失败: " + err.Error(),
+		})
+		return
+	}
+	if channel == nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "通知渠道不存在",
+		})
+		return
+	}
+
+	testNotifier(c, *channel)
+}
+
+// testNotifier 根据渠道配置发送测试消息并返回结果
+func testNotifier(c *gin.Context, channel database.NotifyChannel) {
+	n, err := notifier.Build(channel)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "渠道配置错误: " + err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	if err = n.Test(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  "测试消息发送失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "测试消息发送成功",
+	})
+}
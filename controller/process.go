@@ -0,0 +1,124 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"warnnotice/database"
+	"warnnotice/pkg/e"
+	"warnnotice/util"
+)
+
+// GetProcessWatchers 获取进程监控配置列表
+func GetProcessWatchers(c *gin.Context) {
+	watchers, err := database.GetProcessWatchers(false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  "获取进程监控配置失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "获取进程监控配置成功",
+		"data": watchers,
+	})
+}
+
+// SetProcessWatcher 新增或更新进程监控配置
+func SetProcessWatcher(c *gin.Context) {
+	watcher, ok := bindProcessWatcher(c)
+	if !ok {
+		return
+	}
+
+	id, err := database.SaveProcessWatcher(watcher)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  "保存进程监控配置失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "进程监控配置保存成功",
+		"data": gin.H{
+			"id": id,
+		},
+	})
+}
+
+// DeleteProcessWatcher 删除进程监控配置
+func DeleteProcessWatcher(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	if err = database.DeleteProcessWatcher(id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  "删除进程监控配置失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "进程监控配置删除成功",
+	})
+}
+
+// TestProcessWatcher 查找符合匹配规则的进程，用于保存前确认配置
+func TestProcessWatcher(c *gin.Context) {
+	watcher, ok := bindProcessWatcher(c)
+	if !ok {
+		return
+	}
+
+	processes, err := util.FindProcesses(watcher)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  "查找进程失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "找到" + strconv.Itoa(len(processes)) + "个进程",
+		"data": processes,
+	})
+}
+
+// bindProcessWatcher 解析并校验进程监控配置，失败时直接返回错误响应
+func bindProcessWatcher(c *gin.Context) (util.ProcessWatcher, bool) {
+	// 未提供的字段使用默认值
+	watcher := util.ProcessWatcher{MatchType: util.ProcessMatchName, MinCount: 1, Enabled: true}
+	if err := c.ShouldBindJSON(&watcher); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "参数错误: " + err.Error(),
+		})
+		return watcher, false
+	}
+
+	if err := watcher.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "参数错误: " + err.Error(),
+		})
+		return watcher, false
+	}
+
+	return watcher, true
+}
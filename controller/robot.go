@@ -0,0 +1,52 @@
+package controller
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+	"warnnotice/pkg/e"
+	"warnnotice/util"
+)
+
+// TestRobot 按请求中的机器人配置发送测试消息
+func TestRobot(c *gin.Context) {
+	type RobotTestRequest struct {
+		Type   string           `json:"type"` // dingtalk/wecom/feishu
+		Config util.RobotConfig `json:"config"`
+	}
+
+	var req RobotTestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	if err := util.ValidateRobotConfig(req.Type, req.Config); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.ERROR,
+			"msg":  "请先配置机器人参数: " + err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	err := util.SendTestRobot(ctx, req.Type, req.Config, e.SystemName)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  "机器人消息发送失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "测试机器人消息发送成功",
+	})
+}
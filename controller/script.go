@@ -2,45 +2,53 @@ package controller
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/huobirdcenter/huobi_golang/logging/applogger"
 	"net/http"
 	"strconv"
+	"time"
 	"warnnotice/database"
 	"warnnotice/pkg/e"
 	"warnnotice/scheduler"
 	"warnnotice/util"
 )
 
-// 脚本配置处理函数
-func SetScriptConfig(c *gin.Context) {
-	var config util.ScriptConfig
-	if err := c.ShouldBindJSON(&config); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code": e.INVALID_PARAMS,
-			"msg":  "参数错误: " + err.Error(),
-		})
-		return
-	}
+// scriptStatus 脚本配置及下一次执行时间
+type scriptStatus struct {
+	util.Script
+	NextRun *time.Time `json:"next_run"` // 下一次执行时间，不定时执行时为null
+}
 
-	// 保存到数据库
-	err := database.SaveScriptConfig(config)
+// GetScripts 获取脚本配置列表及下一次执行时间
+func GetScripts(c *gin.Context) {
+	scripts, err := database.GetScripts(false)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code": e.ERROR,
-			"msg":  "保存脚本配置失败: " + err.Error(),
+			"msg":  "获取脚本配置失败: " + err.Error(),
 		})
 		return
 	}
 
-	// 更新全局变量
-	e.ScriptConfig = &config
+	data := make([]scriptStatus, 0, len(scripts))
+	for _, script := range scripts {
+		data = append(data, scriptStatus{Script: script, NextRun: scheduler.NextScriptRun(script.ID)})
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"code": e.SUCCESS,
-		"msg":  "脚本配置保存成功",
+		"msg":  "获取脚本配置成功",
+		"data": data,
 	})
 }
-func GetScriptConfig(c *gin.Context) {
-	config, err := database.GetScriptConfig()
+
+// SetScript 新增或更新脚本配置，只重启该脚本的定时任务
+func SetScript(c *gin.Context) {
+	script, ok := bindScript(c)
+	if !ok {
+		return
+	}
+
+	existing, err := database.GetScriptByName(script.Name)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code": e.ERROR,
@@ -48,66 +56,189 @@ func GetScriptConfig(c *gin.Context) {
 		})
 		return
 	}
-	scheduler.RestartScriptScheduler()
-	c.JSON(http.StatusOK, gin.H{
-		"code": e.SUCCESS,
-		"msg":  "获取脚本配置成功",
-		"data": config,
-	})
-}
-func TestScript(c *gin.Context) {
-	if e.ScriptConfig == nil || e.ScriptConfig.Path == "" {
+	if existing != nil && existing.ID != script.ID {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"code": e.ERROR,
-			"msg":  "请先配置脚本路径",
+			"code": e.INVALID_PARAMS,
+			"msg":  "脚本名称已存在: " + script.Name,
 		})
 		return
 	}
 
-	result, output, err := util.ExecuteScript(*e.ScriptConfig)
+	saveScript(c, script)
+}
+
+// DeleteScript 删除脚本配置及其执行历史
+func DeleteScript(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	if err = database.DeleteScript(id); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code": e.ERROR,
-			"msg":  "脚本执行失败: " + err.Error(),
+			"msg":  "删除脚本配置失败: " + err.Error(),
 		})
 		return
 	}
 
+	reloadScripts()
+
 	c.JSON(http.StatusOK, gin.H{
 		"code": e.SUCCESS,
-		"msg":  "脚本执行成功,结果：" + output,
-		"data": gin.H{
-			"result": result,
-		},
+		"msg":  "脚本配置删除成功",
 	})
 }
 
-func GetScriptHistory(c *gin.Context) {
-	limitStr := c.DefaultQuery("limit", "10")
+// TestScriptConfig 执行请求中的脚本配置，不保存
+func TestScriptConfig(c *gin.Context) {
+	script, ok := bindScript(c)
+	if !ok {
+		return
+	}
+
+	runScriptTest(c, script)
+}
 
-	// 将字符串转换为整数
-	limit, err := strconv.Atoi(limitStr)
+// GetScriptHistoryByID 获取指定脚本的执行历史
+func GetScriptHistoryByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		limit = 10 // 如果转换失败，使用默认值10
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit <= 0 {
+		limit = 10
+	}
+
+	scriptHistory(c, id, limit)
+}
+
+// GetScriptMetrics 获取脚本最近执行的性能数据，按指标分组用于绘制图表
+func GetScriptMetrics(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit <= 0 {
+		limit = 100
 	}
 
-	histories, err := database.GetScriptHistory(limit)
+	series, err := database.GetScriptMetrics(id, limit, c.Query("label"))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code": e.ERROR,
-			"msg":  "获取脚本执行历史失败: " + err.Error(),
+			"msg":  "获取脚本性能数据失败: " + err.Error(),
 		})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"code": e.SUCCESS,
-		"msg":  "获取脚本执行历史成功",
-		"data": histories,
+		"msg":  "获取脚本性能数据成功",
+		"data": series,
+	})
+}
+
+// 脚本配置处理函数，读写名为default的脚本
+func SetScriptConfig(c *gin.Context) {
+	var config util.ScriptConfig
+	if err := c.ShouldBindJSON(&config); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	script, ok := defaultScript(c)
+	if !ok {
+		return
+	}
+	if script == nil {
+		script = &util.Script{Name: util.DefaultScriptName, Returns: map[int]string{}, Enabled: true}
+	}
+	// 旧接口只包含路径、参数、超时和间隔，保留脚本已有的执行模式和定时配置
+	script.Path = config.Path
+	script.Parameters = config.Parameters
+	script.Timeout = config.Timeout
+	script.Interval = config.Interval
+	if err := script.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	saveScript(c, *script)
+}
+
+func GetScriptConfig(c *gin.Context) {
+	script, ok := defaultScript(c)
+	if !ok {
+		return
+	}
+
+	// 返回脚本配置及下一次执行时间
+	type scriptConfigStatus struct {
+		util.ScriptConfig
+		NextRun *time.Time `json:"next_run"`
+	}
+	var config *scriptConfigStatus
+	if script != nil {
+		config = &scriptConfigStatus{ScriptConfig: script.ScriptConfig, NextRun: scheduler.NextScriptRun(script.ID)}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "获取脚本配置成功",
+		"data": config,
 	})
 }
 
-// 脚本返回值配置处理函数
+func TestScript(c *gin.Context) {
+	script, ok := defaultScript(c)
+	if !ok {
+		return
+	}
+	if script == nil || script.Path == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.ERROR,
+			"msg":  "请先配置脚本路径",
+		})
+		return
+	}
+
+	runScriptTest(c, *script)
+}
+
+// GetScriptHistory 获取所有脚本的执行历史，可通过script_id筛选
+func GetScriptHistory(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil {
+		limit = 10 // 如果转换失败，使用默认值10
+	}
+	scriptID, _ := strconv.Atoi(c.Query("script_id"))
+
+	scriptHistory(c, scriptID, limit)
+}
+
+// 脚本返回值配置处理函数，修改名为default的脚本，告警文本为空时删除该返回值的配置
 func SetScriptReturnConfig(c *gin.Context) {
 	type ScriptReturnConfigRequest struct {
 		ReturnValue int    `json:"return_value"`
@@ -123,41 +254,158 @@ func SetScriptReturnConfig(c *gin.Context) {
 		return
 	}
 
-	// 保存到数据库
-	err := database.SaveScriptReturnConfig(req.ReturnValue, req.AlertText)
+	script, ok := defaultScript(c)
+	if !ok {
+		return
+	}
+	if script == nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.ERROR,
+			"msg":  "请先配置脚本路径",
+		})
+		return
+	}
+
+	if req.AlertText == "" {
+		delete(script.Returns, req.ReturnValue)
+	} else {
+		script.Returns[req.ReturnValue] = req.AlertText
+	}
+	if err := script.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	saveScript(c, *script)
+}
+
+func GetScriptReturnConfigs(c *gin.Context) {
+	script, ok := defaultScript(c)
+	if !ok {
+		return
+	}
+
+	configs := map[int]string{}
+	if script != nil {
+		configs = script.Returns
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "获取脚本返回值配置成功",
+		"data": configs,
+	})
+}
+
+// bindScript 解析并校验脚本配置，失败时直接返回错误响应
+func bindScript(c *gin.Context) (util.Script, bool) {
+	// 未提供时默认启用
+	script := util.Script{Enabled: true}
+	if err := c.ShouldBindJSON(&script); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "参数错误: " + err.Error(),
+		})
+		return script, false
+	}
+
+	if err := script.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "参数错误: " + err.Error(),
+		})
+		return script, false
+	}
+
+	return script, true
+}
+
+// defaultScript 获取名为default的脚本，不存在时返回nil，失败时直接返回错误响应
+func defaultScript(c *gin.Context) (*util.Script, bool) {
+	script, err := database.GetScriptByName(util.DefaultScriptName)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code": e.ERROR,
-			"msg":  "保存脚本返回值配置失败: " + err.Error(),
+			"msg":  "获取脚本配置失败: " + err.Error(),
+		})
+		return nil, false
+	}
+	return script, true
+}
+
+// saveScript 保存脚本配置并重新加载脚本任务
+func saveScript(c *gin.Context, script util.Script) {
+	id, err := database.SaveScript(script)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  "保存脚本配置失败: " + err.Error(),
 		})
 		return
 	}
 
-	// 更新全局变量
-	if e.ScriptReturnConfig == nil {
-		e.ScriptReturnConfig = make(map[int]string)
+	reloadScripts()
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "脚本配置保存成功",
+		"data": gin.H{
+			"id": id,
+		},
+	})
+}
+
+// reloadScripts 配置已保存，重新加载失败时只记录日志
+func reloadScripts() {
+	if err := scheduler.ReloadScripts(); err != nil {
+		applogger.Error("重新加载脚本任务失败: %v", err)
+	}
+}
+
+func runScriptTest(c *gin.Context, script util.Script) {
+	result, output, err := util.ExecuteScript(script.ScriptConfig)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  "脚本执行失败: " + err.Error(),
+		})
+		return
 	}
-	e.ScriptReturnConfig[req.ReturnValue] = req.AlertText
 
+	data := gin.H{
+		"result": result,
+	}
+	if text := script.Returns[result]; text != "" {
+		data["alert_text"] = text
+	}
+	if script.Mode == util.ScriptModeNagios {
+		status, metrics := util.ParseNagiosOutput(output)
+		data["state"] = util.NagiosStatusName(result)
+		data["status"] = status
+		data["metrics"] = metrics
+	}
 	c.JSON(http.StatusOK, gin.H{
 		"code": e.SUCCESS,
-		"msg":  "脚本返回值配置保存成功",
+		"msg":  "脚本执行成功,结果：" + output,
+		"data": data,
 	})
 }
 
-func GetScriptReturnConfigs(c *gin.Context) {
-	configs, err := database.GetAllScriptReturnConfigs()
+func scriptHistory(c *gin.Context, scriptID, limit int) {
+	histories, err := database.GetScriptHistory(scriptID, limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code": e.ERROR,
-			"msg":  "获取脚本返回值配置失败: " + err.Error(),
+			"msg":  "获取脚本执行历史失败: " + err.Error(),
 		})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"code": e.SUCCESS,
-		"msg":  "获取脚本返回值配置成功",
-		"data": configs,
+		"msg":  "获取脚本执行历史成功",
+		"data": histories,
 	})
 }
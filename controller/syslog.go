@@ -0,0 +1,234 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"warnnotice/database"
+	"warnnotice/pkg/e"
+	"warnnotice/scheduler"
+	"warnnotice/util"
+)
+
+// GetSyslogConfig 获取syslog接收配置
+func GetSyslogConfig(c *gin.Context) {
+	config := util.DefaultSyslogConfig()
+	if e.SyslogConfig != nil {
+		config = *e.SyslogConfig
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "获取syslog配置成功",
+		"data": config,
+	})
+}
+
+// SetSyslogConfig 保存syslog接收配置并重新监听
+func SetSyslogConfig(c *gin.Context) {
+	// 以当前配置为基础，请求中未提供的字段保持不变
+	config := util.DefaultSyslogConfig()
+	if e.SyslogConfig != nil {
+		config = *e.SyslogConfig
+	}
+	if err := c.ShouldBindJSON(&config); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	if err := config.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	if err := database.SaveSyslogConfig(config); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  "保存syslog配置失败: " + err.Error(),
+		})
+		return
+	}
+
+	e.SyslogConfig = &config
+	scheduler.RestartSyslogScheduler()
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "syslog配置保存成功",
+	})
+}
+
+// GetSyslogRules 获取syslog匹配规则列表
+func GetSyslogRules(c *gin.Context) {
+	rules, err := database.GetSyslogRules(false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  "获取syslog规则失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "获取syslog规则成功",
+		"data": rules,
+	})
+}
+
+// SetSyslogRule 新增或更新syslog匹配规则
+func SetSyslogRule(c *gin.Context) {
+	rule, ok := bindSyslogRule(c)
+	if !ok {
+		return
+	}
+
+	id, err := database.SaveSyslogRule(rule)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  "保存syslog规则失败: " + err.Error(),
+		})
+		return
+	}
+
+	if err = scheduler.ReloadSyslogRules(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  "加载syslog规则失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "syslog规则保存成功",
+		"data": gin.H{
+			"id": id,
+		},
+	})
+}
+
+// DeleteSyslogRule 删除syslog匹配规则
+func DeleteSyslogRule(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	if err = database.DeleteSyslogRule(id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  "删除syslog规则失败: " + err.Error(),
+		})
+		return
+	}
+
+	if err = scheduler.ReloadSyslogRules(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  "加载syslog规则失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "syslog规则删除成功",
+	})
+}
+
+// TestSyslogRule 用最近收到的消息测试匹配规则，返回匹配的消息
+func TestSyslogRule(c *gin.Context) {
+	rule, ok := bindSyslogRule(c)
+	if !ok {
+		return
+	}
+
+	config := util.DefaultSyslogConfig()
+	if e.SyslogConfig != nil {
+		config = *e.SyslogConfig
+	}
+	messages, err := database.GetSyslogMessages(config.MaxMessages, false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  "获取syslog消息失败: " + err.Error(),
+		})
+		return
+	}
+
+	matched, err := util.MatchSyslogMessages(rule, messages)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "已匹配最近" + strconv.Itoa(len(messages)) + "条消息",
+		"data": matched,
+	})
+}
+
+// GetSyslogMessages 获取最近收到的syslog消息，matched=true时只返回匹配了规则的消息
+func GetSyslogMessages(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "参数错误: limit必须为正整数",
+		})
+		return
+	}
+
+	messages, err := database.GetSyslogMessages(limit, c.Query("matched") == "true")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  "获取syslog消息失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "获取syslog消息成功",
+		"data": messages,
+	})
+}
+
+// bindSyslogRule 解析并校验syslog匹配规则，失败时直接返回错误响应
+func bindSyslogRule(c *gin.Context) (util.SyslogRule, bool) {
+	rule := util.SyslogRule{Enabled: true}
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "参数错误: " + err.Error(),
+		})
+		return rule, false
+	}
+
+	if err := rule.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "参数错误: " + err.Error(),
+		})
+		return rule, false
+	}
+
+	return rule, true
+}
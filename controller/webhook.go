@@ -0,0 +1,47 @@
+package controller
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+	"warnnotice/pkg/e"
+	"warnnotice/util"
+)
+
+// TestWebhook 按请求中的Webhook配置发送测试消息
+func TestWebhook(c *gin.Context) {
+	var config util.WebhookConfig
+	if err := c.ShouldBindJSON(&config); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	if err := util.ValidateWebhookConfig(config); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.ERROR,
+			"msg":  "请先配置Webhook参数: " + err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	err := util.SendTestWebhook(ctx, config, e.SystemName)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  "Webhook发送失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "测试Webhook发送成功",
+	})
+}
@@ -0,0 +1,88 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"warnnotice/util"
+)
+
+const apiKeyColumns = "id, source, prefix, enabled, last_used_at, created_at"
+
+func scanAPIKey(s scanner) (*util.APIKey, error) {
+	var k util.APIKey
+	if err := s.Scan(&k.ID, &k.Source, &k.Prefix, &k.Enabled, &k.LastUsedAt, &k.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+// CreateAPIKey 新增API密钥，只保存密钥的哈希
+func CreateAPIKey(k util.APIKey, keyHash string) (int, error) {
+	result, err := DB.Exec("INSERT INTO api_key (source, key_hash, prefix, enabled) VALUES (?, ?, ?, ?)",
+		k.Source, keyHash, k.Prefix, k.Enabled)
+	if err != nil {
+		return 0, fmt.Errorf("插入API密钥失败: %v", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("获取API密钥ID失败: %v", err)
+	}
+	return int(id), nil
+}
+
+// DeleteAPIKey 删除API密钥
+func DeleteAPIKey(id int) error {
+	_, err := DB.Exec("DELETE FROM api_key WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("删除API密钥失败: %v", err)
+	}
+	return nil
+}
+
+// GetAPIKeyByHash 根据密钥哈希获取已启用的API密钥，不存在时返回nil
+func GetAPIKeyByHash(keyHash string) (*util.APIKey, error) {
+	row := DB.QueryRow("SELECT "+apiKeyColumns+" FROM api_key WHERE key_hash = ? AND enabled = 1", keyHash)
+
+	k, err := scanAPIKey(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("查询API密钥失败: %v", err)
+	}
+
+	return k, nil
+}
+
+// MarkAPIKeyUsed 记录API密钥的使用时间
+func MarkAPIKeyUsed(id int, usedAt int64) error {
+	_, err := DB.Exec("UPDATE api_key SET last_used_at = ? WHERE id = ?", usedAt, id)
+	if err != nil {
+		return fmt.Errorf("更新API密钥使用时间失败: %v", err)
+	}
+	return nil
+}
+
+// GetAPIKeys 获取所有API密钥，不包括密钥本身
+func GetAPIKeys() ([]util.APIKey, error) {
+	rows, err := DB.Query("SELECT " + apiKeyColumns + " FROM api_key ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("查询API密钥失败: %v", err)
+	}
+	defer rows.Close()
+
+	var keys []util.APIKey
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("扫描API密钥失败: %v", err)
+		}
+		keys = append(keys, *k)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("遍历结果时出错: %v", err)
+	}
+
+	return keys, nil
+}
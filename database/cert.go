@@ -0,0 +1,61 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+	"warnnotice/util"
+)
+
+// SaveCertificate 保存健康检查最近一次获取到的证书
+func SaveCertificate(checkID int, cert util.CertificateInfo) error {
+	sans, err := json.Marshal(cert.SANs)
+	if err != nil {
+		return fmt.Errorf("序列化证书域名失败: %v", err)
+	}
+
+	_, err = DB.Exec(`INSERT INTO certificate (check_id, subject, issuer, sans, serial_number, not_before, not_after, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(check_id) DO UPDATE SET subject = excluded.subject, issuer = excluded.issuer, sans = excluded.sans,
+		serial_number = excluded.serial_number, not_before = excluded.not_before, not_after = excluded.not_after, updated_at = excluded.updated_at`,
+		checkID, cert.Subject, cert.Issuer, string(sans), cert.SerialNumber, cert.NotBefore, cert.NotAfter, time.Now().Unix())
+	if err != nil {
+		return fmt.Errorf("保存证书信息失败: %v", err)
+	}
+	return nil
+}
+
+// GetCertificateStatuses 获取所有证书及剩余有效期，按到期时间排序
+func GetCertificateStatuses() ([]util.CertificateStatus, error) {
+	rows, err := DB.Query(`SELECT h.id, h.name, h.target, COALESCE(c.subject, ''), COALESCE(c.issuer, ''), COALESCE(c.sans, ''),
+		COALESCE(c.serial_number, ''), c.not_before, c.not_after, c.updated_at
+		FROM certificate c JOIN health_check h ON h.id = c.check_id
+		ORDER BY c.not_after`)
+	if err != nil {
+		return nil, fmt.Errorf("查询证书信息失败: %v", err)
+	}
+	defer rows.Close()
+
+	now := time.Now()
+	statuses := make([]util.CertificateStatus, 0)
+	for rows.Next() {
+		var s util.CertificateStatus
+		var sans string
+		err = rows.Scan(&s.CheckID, &s.Name, &s.Target, &s.Subject, &s.Issuer, &sans, &s.SerialNumber, &s.NotBefore, &s.NotAfter, &s.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("读取证书信息失败: %v", err)
+		}
+		if sans != "" {
+			if err = json.Unmarshal([]byte(sans), &s.SANs); err != nil {
+				return nil, fmt.Errorf("解析证书域名失败: %v", err)
+			}
+		}
+		s.DaysRemaining = s.CertificateInfo.DaysRemaining(now)
+		statuses = append(statuses, s)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("读取证书信息失败: %v", err)
+	}
+
+	return statuses, nil
+}
@@ -0,0 +1,115 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+)
+
+// NotifyChannel 通知渠道配置结构
+type NotifyChannel struct {
+	ID          int             `json:"id"`
+	Name        string          `json:"name"`
+	Type        string          `json:"type"`
+	Config      json.RawMessage `json:"config"` // 渠道配置，具体结构由渠道类型决定
+	Enabled     bool            `json:"enabled"`
+	MinSeverity string          `json:"min_severity"` // 最低告警级别，为空时接收所有级别
+	CreatedAt   string          `json:"created_at"`
+}
+
+// SaveNotifyChannel 保存通知渠道，ID为0时新增，否则更新
+func SaveNotifyChannel(channel NotifyChannel) (int, error) {
+	config := string(channel.Config)
+	if config == "" {
+		config = "{}"
+	}
+
+	if channel.ID == 0 {
+		result, err := DB.Exec("INSERT INTO notify_channel (name, type, config, enabled, min_severity) VALUES (?, ?, ?, ?, ?)",
+			channel.Name, channel.Type, config, channel.Enabled, channel.MinSeverity)
+		if err != nil {
+			return 0, fmt.Errorf("插入通知渠道失败: %v", err)
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			return 0, fmt.Errorf("获取通知渠道ID失败: %v", err)
+		}
+		return int(id), nil
+	}
+
+	result, err := DB.Exec("UPDATE notify_channel SET name = ?, type = ?, config = ?, enabled = ?, min_severity = ? WHERE id = ?",
+		channel.Name, channel.Type, config, channel.Enabled, channel.MinSeverity, channel.ID)
+	if err != nil {
+		return 0, fmt.Errorf("更新通知渠道失败: %v", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return 0, fmt.Errorf("通知渠道不存在: %d", channel.ID)
+	}
+
+	return channel.ID, nil
+}
+
+// DeleteNotifyChannel 删除通知渠道
+func DeleteNotifyChannel(id int) error {
+	_, err := DB.Exec("DELETE FROM notify_channel WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("删除通知渠道失败: %v", err)
+	}
+	return nil
+}
+
+// GetNotifyChannel 获取单个通知渠道
+func GetNotifyChannel(id int) (*NotifyChannel, error) {
+	row := DB.QueryRow("SELECT id, name, type, config, enabled, COALESCE(min_severity, ''), created_at FROM notify_channel WHERE id = ?", id)
+
+	channel, err := scanNotifyChannel(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("查询通知渠道失败: %v", err)
+	}
+
+	return channel, nil
+}
+
+// GetNotifyChannels 获取所有通知渠道
+func GetNotifyChannels() ([]NotifyChannel, error) {
+	rows, err := DB.Query("SELECT id, name, type, config, enabled, COALESCE(min_severity, ''), created_at FROM notify_channel ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("查询通知渠道失败: %v", err)
+	}
+	defer rows.Close()
+
+	var channels []NotifyChannel
+	for rows.Next() {
+		channel, err := scanNotifyChannel(rows)
+		if err != nil {
+			return nil, fmt.Errorf("扫描通知渠道失败: %v", err)
+		}
+		channels = append(channels, *channel)
+	}
+
+	// 检查迭代过程中是否有错误
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("遍历结果时出错: %v", err)
+	}
+
+	return channels, nil
+}
+
+// scanner 兼容 sql.Row 和 sql.Rows 的扫描接口
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanNotifyChannel(s scanner) (*NotifyChannel, error) {
+	var channel NotifyChannel
+	var config string
+	err := s.Scan(&channel.ID, &channel.Name, &channel.Type, &config, &channel.Enabled, &channel.MinSeverity, &channel.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	channel.Config = json.RawMessage(config)
+	return &channel, nil
+}
@@ -0,0 +1,228 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"warnnotice/util"
+)
+
+const checkResultColumns = "id, check_id, success, latency, status_code, COALESCE(message, ''), COALESCE(value, 0), timestamp"
+
+const checkColumns = "id, name, type, target, timeout, interval, failure_threshold, COALESCE(severity, ''), enabled, COALESCE(http_config, ''), COALESCE(tls_config, ''), COALESCE(file_config, ''), COALESCE(schedule, ''), created_at"
+
+func scanCheck(s scanner) (*util.Check, error) {
+	var c util.Check
+	var httpConfig, tlsConfig, fileConfig, schedule string
+	err := s.Scan(&c.ID, &c.Name, &c.Type, &c.Target, &c.Timeout, &c.Interval, &c.FailureThreshold, &c.Severity, &c.Enabled, &httpConfig, &tlsConfig,
+		&fileConfig, &schedule, &c.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if httpConfig != "" {
+		if err = json.Unmarshal([]byte(httpConfig), &c.HTTP); err != nil {
+			return nil, fmt.Errorf("解析HTTP检查配置失败: %v", err)
+		}
+	}
+	if tlsConfig != "" {
+		if err = json.Unmarshal([]byte(tlsConfig), &c.TLS); err != nil {
+			return nil, fmt.Errorf("解析证书检查配置失败: %v", err)
+		}
+	}
+	if fileConfig != "" {
+		if err = json.Unmarshal([]byte(fileConfig), &c.File); err != nil {
+			return nil, fmt.Errorf("解析文件检查配置失败: %v", err)
+		}
+	}
+	if schedule != "" {
+		if err = json.Unmarshal([]byte(schedule), &c.Schedule); err != nil {
+			return nil, fmt.Errorf("解析定时执行配置失败: %v", err)
+		}
+	} else {
+		// 旧版本的检查启动时立即执行
+		c.Schedule.RunOnStartup = true
+	}
+	return &c, nil
+}
+
+// SaveCheck 保存健康检查配置，ID为0时新增，否则更新
+func SaveCheck(c util.Check) (int, error) {
+	httpConfig, err := json.Marshal(c.HTTP)
+	if err != nil {
+		return 0, fmt.Errorf("序列化HTTP检查配置失败: %v", err)
+	}
+	tlsConfig, err := json.Marshal(c.TLS)
+	if err != nil {
+		return 0, fmt.Errorf("序列化证书检查配置失败: %v", err)
+	}
+	fileConfig, err := json.Marshal(c.File)
+	if err != nil {
+		return 0, fmt.Errorf("序列化文件检查配置失败: %v", err)
+	}
+	schedule, err := json.Marshal(c.Schedule)
+	if err != nil {
+		return 0, fmt.Errorf("序列化定时执行配置失败: %v", err)
+	}
+
+	if c.ID == 0 {
+		result, err := DB.Exec(`INSERT INTO health_check (name, type, target, timeout, interval, failure_threshold, severity, enabled, http_config, tls_config,
+			file_config, schedule) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			c.Name, c.Type, c.Target, c.Timeout, c.Interval, c.FailureThreshold, c.Severity, c.Enabled, string(httpConfig), string(tlsConfig), string(fileConfig),
+			string(schedule))
+		if err != nil {
+			return 0, fmt.Errorf("插入健康检查配置失败: %v", err)
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			return 0, fmt.Errorf("获取健康检查配置ID失败: %v", err)
+		}
+		return int(id), nil
+	}
+
+	result, err := DB.Exec(`UPDATE health_check SET name = ?, type = ?, target = ?, timeout = ?, interval = ?, failure_threshold = ?, severity = ?,
+		enabled = ?, http_config = ?, tls_config = ?, file_config = ?, schedule = ? WHERE id = ?`,
+		c.Name, c.Type, c.Target, c.Timeout, c.Interval, c.FailureThreshold, c.Severity, c.Enabled, string(httpConfig), string(tlsConfig), string(fileConfig),
+		string(schedule), c.ID)
+	if err != nil {
+		return 0, fmt.Errorf("更新健康检查配置失败: %v", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return 0, fmt.Errorf("健康检查配置不存在: %d", c.ID)
+	}
+
+	return c.ID, nil
+}
+
+// DeleteCheck 删除健康检查配置及其检查历史
+func DeleteCheck(id int) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("开始事务失败: %v", err)
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec("DELETE FROM health_check WHERE id = ?", id); err != nil {
+		return fmt.Errorf("删除健康检查配置失败: %v", err)
+	}
+	if _, err = tx.Exec("DELETE FROM check_history WHERE check_id = ?", id); err != nil {
+		return fmt.Errorf("删除健康检查历史失败: %v", err)
+	}
+	if _, err = tx.Exec("DELETE FROM certificate WHERE check_id = ?", id); err != nil {
+		return fmt.Errorf("删除证书信息失败: %v", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("提交事务失败: %v", err)
+	}
+	return nil
+}
+
+// GetCheck 获取单个健康检查配置
+func GetCheck(id int) (*util.Check, error) {
+	row := DB.QueryRow("SELECT "+checkColumns+" FROM health_check WHERE id = ?", id)
+
+	c, err := scanCheck(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("查询健康检查配置失败: %v", err)
+	}
+
+	return c, nil
+}
+
+// GetChecks 获取健康检查配置，enabledOnly为true时只返回已启用的配置
+func GetChecks(enabledOnly bool) ([]util.Check, error) {
+	query := "SELECT " + checkColumns + " FROM health_check"
+	if enabledOnly {
+		query += " WHERE enabled = 1"
+	}
+	rows, err := DB.Query(query + " ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("查询健康检查配置失败: %v", err)
+	}
+	defer rows.Close()
+
+	var checks []util.Check
+	for rows.Next() {
+		c, err := scanCheck(rows)
+		if err != nil {
+			return nil, fmt.Errorf("扫描健康检查配置失败: %v", err)
+		}
+		checks = append(checks, *c)
+	}
+
+	// 检查迭代过程中是否有错误
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("遍历结果时出错: %v", err)
+	}
+
+	return checks, nil
+}
+
+// SaveCheckResult 保存健康检查结果
+func SaveCheckResult(result util.CheckResult) error {
+	_, err := DB.Exec("INSERT INTO check_history (check_id, success, latency, status_code, message, value, timestamp) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		result.CheckID, result.Success, result.Latency, result.StatusCode, result.Message, result.Value, result.Timestamp)
+	if err != nil {
+		return fmt.Errorf("插入健康检查结果失败: %v", err)
+	}
+	return nil
+}
+
+// GetCheckHistory 获取健康检查历史（按时间倒序）
+func GetCheckHistory(checkID, limit int) ([]util.CheckResult, error) {
+	return queryCheckResults("SELECT "+checkResultColumns+" FROM check_history WHERE check_id = ? ORDER BY id DESC LIMIT ?", checkID, limit)
+}
+
+// GetFirstCheckResultSince 获取指定时间之后最早的一次成功的检查结果，没有时返回nil
+func GetFirstCheckResultSince(checkID int, since int64) (*util.CheckResult, error) {
+	results, err := queryCheckResults("SELECT "+checkResultColumns+" FROM check_history "+
+		"WHERE check_id = ? AND success = 1 AND timestamp >= ? ORDER BY timestamp, id LIMIT 1", checkID, since)
+	if err != nil || len(results) == 0 {
+		return nil, err
+	}
+	return &results[0], nil
+}
+
+// GetLatestCheckResults 获取各健康检查的最近一次结果
+func GetLatestCheckResults() (map[int]util.CheckResult, error) {
+	results, err := queryCheckResults("SELECT " + checkResultColumns + " FROM check_history " +
+		"WHERE id IN (SELECT MAX(id) FROM check_history GROUP BY check_id)")
+	if err != nil {
+		return nil, err
+	}
+
+	latest := make(map[int]util.CheckResult, len(results))
+	for _, result := range results {
+		latest[result.CheckID] = result
+	}
+	return latest, nil
+}
+
+func queryCheckResults(query string, args ...interface{}) ([]util.CheckResult, error) {
+	rows, err := DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询健康检查历史失败: %v", err)
+	}
+	defer rows.Close()
+
+	var results []util.CheckResult
+	for rows.Next() {
+		var result util.CheckResult
+		err := rows.Scan(&result.ID, &result.CheckID, &result.Success, &result.Latency, &result.StatusCode, &result.Message, &result.Value,
+			&result.Timestamp)
+		if err != nil {
+			return nil, fmt.Errorf("扫描健康检查历史失败: %v", err)
+		}
+		results = append(results, result)
+	}
+
+	// 检查迭代过程中是否有错误
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("遍历结果时出错: %v", err)
+	}
+
+	return results, nil
+}
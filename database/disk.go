@@ -0,0 +1,73 @@
+package database
+
+import (
+	"fmt"
+	"warnnotice/util"
+)
+
+// SaveDiskStatus 保存各磁盘分区的状态
+func SaveDiskStatus(timestamp int64, stats map[string]util.DiskStat) error {
+	if len(stats) == 0 {
+		return nil
+	}
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("开始事务失败: %v", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`INSERT INTO disk_status (timestamp, mountpoint, fstype, total, free, used_percent, inodes_total, inodes_free, inodes_used_percent)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("准备插入语句失败: %v", err)
+	}
+	defer stmt.Close()
+
+	for mountPoint, stat := range stats {
+		_, err = stmt.Exec(timestamp, mountPoint, stat.Fstype, stat.Total, stat.Free, stat.UsedPercent,
+			stat.InodesTotal, stat.InodesFree, stat.InodesUsedPercent)
+		if err != nil {
+			return fmt.Errorf("插入磁盘分区状态失败: %v", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("提交事务失败: %v", err)
+	}
+
+	return nil
+}
+
+// GetDiskStatusSince 获取指定时间之后的磁盘分区状态，按时间戳和挂载点分组
+func GetDiskStatusSince(since int64) (map[int64]map[string]util.DiskStat, error) {
+	rows, err := DB.Query(`SELECT timestamp, mountpoint, COALESCE(fstype, ''), total, free, used_percent, inodes_total, inodes_free, inodes_used_percent
+		FROM disk_status WHERE timestamp >= ?`, since)
+	if err != nil {
+		return nil, fmt.Errorf("查询磁盘分区状态失败: %v", err)
+	}
+	defer rows.Close()
+
+	result := make(map[int64]map[string]util.DiskStat)
+	for rows.Next() {
+		var timestamp int64
+		var mountPoint string
+		var stat util.DiskStat
+		err := rows.Scan(&timestamp, &mountPoint, &stat.Fstype, &stat.Total, &stat.Free, &stat.UsedPercent,
+			&stat.InodesTotal, &stat.InodesFree, &stat.InodesUsedPercent)
+		if err != nil {
+			return nil, fmt.Errorf("扫描磁盘分区状态失败: %v", err)
+		}
+		if result[timestamp] == nil {
+			result[timestamp] = make(map[string]util.DiskStat)
+		}
+		result[timestamp][mountPoint] = stat
+	}
+
+	// 检查迭代过程中是否有错误
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("遍历结果时出错: %v", err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,158 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"warnnotice/util"
+)
+
+const heartbeatColumns = "id, name, token, period, grace, COALESCE(severity, ''), enabled, last_ping_at, COALESCE(last_ping_kind, ''), created_at"
+
+func scanHeartbeat(s scanner) (*util.Heartbeat, error) {
+	var h util.Heartbeat
+	err := s.Scan(&h.ID, &h.Name, &h.Token, &h.Period, &h.Grace, &h.Severity, &h.Enabled, &h.LastPingAt, &h.LastPingKind, &h.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
+
+// SaveHeartbeat 保存心跳监控配置，ID为0时新增并生成令牌，更新时不修改令牌
+func SaveHeartbeat(h util.Heartbeat) (int, error) {
+	if h.ID == 0 {
+		result, err := DB.Exec("INSERT INTO heartbeat (name, token, period, grace, severity, enabled) VALUES (?, ?, ?, ?, ?, ?)",
+			h.Name, h.Token, h.Period, h.Grace, h.Severity, h.Enabled)
+		if err != nil {
+			return 0, fmt.Errorf("插入心跳监控配置失败: %v", err)
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			return 0, fmt.Errorf("获取心跳监控配置ID失败: %v", err)
+		}
+		return int(id), nil
+	}
+
+	result, err := DB.Exec("UPDATE heartbeat SET name = ?, period = ?, grace = ?, severity = ?, enabled = ? WHERE id = ?",
+		h.Name, h.Period, h.Grace, h.Severity, h.Enabled, h.ID)
+	if err != nil {
+		return 0, fmt.Errorf("更新心跳监控配置失败: %v", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return 0, fmt.Errorf("心跳监控配置不存在: %d", h.ID)
+	}
+
+	return h.ID, nil
+}
+
+// DeleteHeartbeat 删除心跳监控配置及其ping记录
+func DeleteHeartbeat(id int) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("开始事务失败: %v", err)
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec("DELETE FROM heartbeat WHERE id = ?", id); err != nil {
+		return fmt.Errorf("删除心跳监控配置失败: %v", err)
+	}
+	if _, err = tx.Exec("DELETE FROM heartbeat_ping WHERE heartbeat_id = ?", id); err != nil {
+		return fmt.Errorf("删除心跳ping记录失败: %v", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("提交事务失败: %v", err)
+	}
+	return nil
+}
+
+// GetHeartbeatByToken 根据令牌获取心跳监控配置
+func GetHeartbeatByToken(token string) (*util.Heartbeat, error) {
+	row := DB.QueryRow("SELECT "+heartbeatColumns+" FROM heartbeat WHERE token = ?", token)
+
+	h, err := scanHeartbeat(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("查询心跳监控配置失败: %v", err)
+	}
+
+	return h, nil
+}
+
+// GetHeartbeats 获取心跳监控配置，enabledOnly为true时只返回已启用的配置
+func GetHeartbeats(enabledOnly bool) ([]util.Heartbeat, error) {
+	query := "SELECT " + heartbeatColumns + " FROM heartbeat"
+	if enabledOnly {
+		query += " WHERE enabled = 1"
+	}
+	rows, err := DB.Query(query + " ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("查询心跳监控配置失败: %v", err)
+	}
+	defer rows.Close()
+
+	var heartbeats []util.Heartbeat
+	for rows.Next() {
+		h, err := scanHeartbeat(rows)
+		if err != nil {
+			return nil, fmt.Errorf("扫描心跳监控配置失败: %v", err)
+		}
+		heartbeats = append(heartbeats, *h)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("遍历结果时出错: %v", err)
+	}
+
+	return heartbeats, nil
+}
+
+// SaveHeartbeatPing 保存ping记录并更新心跳的最近一次ping
+func SaveHeartbeatPing(ping util.HeartbeatPing) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("开始事务失败: %v", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("INSERT INTO heartbeat_ping (heartbeat_id, kind, body, remote_addr, timestamp) VALUES (?, ?, ?, ?, ?)",
+		ping.HeartbeatID, ping.Kind, ping.Body, ping.RemoteAddr, ping.Timestamp)
+	if err != nil {
+		return fmt.Errorf("插入心跳ping记录失败: %v", err)
+	}
+	_, err = tx.Exec("UPDATE heartbeat SET last_ping_at = ?, last_ping_kind = ? WHERE id = ?", ping.Timestamp, ping.Kind, ping.HeartbeatID)
+	if err != nil {
+		return fmt.Errorf("更新心跳最近一次ping失败: %v", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("提交事务失败: %v", err)
+	}
+	return nil
+}
+
+// GetHeartbeatPings 获取心跳ping记录（按时间倒序）
+func GetHeartbeatPings(heartbeatID, limit int) ([]util.HeartbeatPing, error) {
+	rows, err := DB.Query("SELECT id, heartbeat_id, kind, COALESCE(body, ''), COALESCE(remote_addr, ''), timestamp FROM heartbeat_ping "+
+		"WHERE heartbeat_id = ? ORDER BY id DESC LIMIT ?", heartbeatID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("查询心跳ping记录失败: %v", err)
+	}
+	defer rows.Close()
+
+	var pings []util.HeartbeatPing
+	for rows.Next() {
+		var p util.HeartbeatPing
+		if err = rows.Scan(&p.ID, &p.HeartbeatID, &p.Kind, &p.Body, &p.RemoteAddr, &p.Timestamp); err != nil {
+			return nil, fmt.Errorf("扫描心跳ping记录失败: %v", err)
+		}
+		pings = append(pings, p)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("遍历结果时出错: %v", err)
+	}
+
+	return pings, nil
+}
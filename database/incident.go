@@ -0,0 +1,344 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+	"warnnotice/util"
+)
+
+// IncidentStatusAcknowledged 告警事件已确认，确认后不再重复通知直到恢复
+const IncidentStatusAcknowledged = "acknowledged"
+
+// Incident 告警事件结构
+type Incident struct {
+	ID             int    `json:"id"`
+	AlertKey       string `json:"alert_key"`
+	Source         string `json:"source"`
+	Title          string `json:"title"`
+	Severity       string `json:"severity"`
+	Status         string `json:"status"`
+	Message        string `json:"message"`
+	StartedAt      int64  `json:"started_at"`
+	ResolvedAt     int64  `json:"resolved_at"`
+	ResolvedBy     string `json:"resolved_by"`
+	AcknowledgedBy string `json:"acknowledged_by"`
+	AcknowledgedAt int64  `json:"acknowledged_at"`
+	NotifyCount    int    `json:"notify_count"`
+	LastNotifiedAt int64  `json:"last_notified_at"`
+	Duration       int64  `json:"duration"` // 持续时间(秒)，未恢复时为0
+	CreatedAt      string `json:"created_at"`
+}
+
+// IncidentNote 告警事件备注结构
+type IncidentNote struct {
+	ID         int    `json:"id"`
+	IncidentID int    `json:"incident_id"`
+	Author     string `json:"author"`
+	Content    string `json:"content"`
+	CreatedAt  string `json:"created_at"`
+}
+
+// IncidentProcess 告警事件的进程快照
+type IncidentProcess struct {
+	ID         int                  `json:"id"`
+	IncidentID int                  `json:"incident_id"`
+	AlertKey   string               `json:"alert_key"`
+	Snapshot   util.ProcessSnapshot `json:"snapshot"`
+	CreatedAt  string               `json:"created_at"`
+}
+
+const incidentColumns = "id, alert_key, source, title, severity, status, COALESCE(message, ''), started_at, resolved_at, " +
+	"COALESCE(resolved_by, ''), COALESCE(acknowledged_by, ''), acknowledged_at, notify_count, last_notified_at, created_at"
+
+func scanIncident(s scanner) (*Incident, error) {
+	var incident Incident
+	err := s.Scan(&incident.ID, &incident.AlertKey, &incident.Source, &incident.Title, &incident.Severity, &incident.Status,
+		&incident.Message, &incident.StartedAt, &incident.ResolvedAt, &incident.ResolvedBy, &incident.AcknowledgedBy,
+		&incident.AcknowledgedAt, &incident.NotifyCount, &incident.LastNotifiedAt, &incident.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if incident.ResolvedAt > 0 {
+		incident.Duration = incident.ResolvedAt - incident.StartedAt
+	}
+	return &incident, nil
+}
+
+// GetOpenIncident 获取告警条件未恢复的事件，不存在时返回nil
+func GetOpenIncident(alertKey string) (*Incident, error) {
+	row := DB.QueryRow("SELECT "+incidentColumns+" FROM incidents WHERE alert_key = ? AND status != ? ORDER BY id DESC LIMIT 1",
+		alertKey, util.AlertStatusResolved)
+
+	incident, err := scanIncident(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("查询告警事件失败: %v", err)
+	}
+
+	return incident, nil
+}
+
+// GetOpenIncidents 获取所有未恢复的告警事件
+func GetOpenIncidents() ([]Incident, error) {
+	rows, err := DB.Query("SELECT "+incidentColumns+" FROM incidents WHERE status != ? ORDER BY id", util.AlertStatusResolved)
+	if err != nil {
+		return nil, fmt.Errorf("查询告警事件失败: %v", err)
+	}
+	defer rows.Close()
+
+	var incidents []Incident
+	for rows.Next() {
+		incident, err := scanIncident(rows)
+		if err != nil {
+			return nil, fmt.Errorf("扫描告警事件失败: %v", err)
+		}
+		incidents = append(incidents, *incident)
+	}
+
+	// 检查迭代过程中是否有错误
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("遍历结果时出错: %v", err)
+	}
+
+	return incidents, nil
+}
+
+// OpenIncident 记录告警事件开始，同一告警条件已有未恢复的事件时归并到该事件
+func OpenIncident(alert util.Alert) (*Incident, error) {
+	incident, err := GetOpenIncident(alert.Key)
+	if err != nil {
+		return nil, err
+	}
+	if incident != nil {
+		// 告警级别升高时更新事件级别
+		if util.SeverityRank(alert.Severity) > util.SeverityRank(incident.Severity) {
+			_, err = DB.Exec("UPDATE incidents SET severity = ? WHERE id = ?", alert.Severity, incident.ID)
+			if err != nil {
+				return nil, fmt.Errorf("更新告警事件级别失败: %v", err)
+			}
+			incident.Severity = alert.Severity
+		}
+		return incident, nil
+	}
+
+	result, err := DB.Exec("INSERT INTO incidents (alert_key, source, title, severity, status, message, started_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		alert.Key, alert.Source, alert.Title, alert.Severity, util.AlertStatusFiring, alert.Message, alert.Timestamp)
+	if err != nil {
+		return nil, fmt.Errorf("插入告警事件失败: %v", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("获取告警事件ID失败: %v", err)
+	}
+
+	return GetIncident(int(id))
+}
+
+// MarkIncidentNotified 记录告警事件的一次通知
+func MarkIncidentNotified(id int, notifiedAt int64) error {
+	_, err := DB.Exec("UPDATE incidents SET notify_count = notify_count + 1, last_notified_at = ? WHERE id = ?", notifiedAt, id)
+	if err != nil {
+		return fmt.Errorf("更新告警事件通知次数失败: %v", err)
+	}
+	return nil
+}
+
+// ResolveIncident 记录告警事件恢复，返回被恢复的事件ID，没有未恢复的事件时返回0
+func ResolveIncident(alertKey string, resolvedAt int64) (int, error) {
+	incident, err := GetOpenIncident(alertKey)
+	if err != nil || incident == nil {
+		return 0, err
+	}
+
+	_, err = DB.Exec("UPDATE incidents SET status = ?, resolved_at = ? WHERE id = ?", util.AlertStatusResolved, resolvedAt, incident.ID)
+	if err != nil {
+		return 0, fmt.Errorf("更新告警事件失败: %v", err)
+	}
+
+	return incident.ID, nil
+}
+
+// AcknowledgeIncident 确认告警事件
+func AcknowledgeIncident(id int, user string) error {
+	result, err := DB.Exec("UPDATE incidents SET status = ?, acknowledged_by = ?, acknowledged_at = ? WHERE id = ? AND status = ?",
+		IncidentStatusAcknowledged, user, time.Now().Unix(), id, util.AlertStatusFiring)
+	if err != nil {
+		return fmt.Errorf("确认告警事件失败: %v", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("告警事件不存在或不处于告警状态")
+	}
+	return nil
+}
+
+// CloseIncident 手动关闭告警事件
+func CloseIncident(id int, user string) error {
+	result, err := DB.Exec("UPDATE incidents SET status = ?, resolved_at = ?, resolved_by = ? WHERE id = ? AND status != ?",
+		util.AlertStatusResolved, time.Now().Unix(), user, id, util.AlertStatusResolved)
+	if err != nil {
+		return fmt.Errorf("关闭告警事件失败: %v", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("告警事件不存在或已恢复")
+	}
+	return nil
+}
+
+// GetIncident 获取单个告警事件
+func GetIncident(id int) (*Incident, error) {
+	row := DB.QueryRow("SELECT "+incidentColumns+" FROM incidents WHERE id = ?", id)
+
+	incident, err := scanIncident(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("查询告警事件失败: %v", err)
+	}
+
+	return incident, nil
+}
+
+// GetIncidents 获取告警事件记录（按时间倒序），status为空时返回所有状态
+func GetIncidents(status string, limit int) ([]Incident, error) {
+	query := "SELECT " + incidentColumns + " FROM incidents"
+	args := make([]interface{}, 0, 2)
+	if status != "" {
+		query += " WHERE status = ?"
+		args = append(args, status)
+	}
+	query += " ORDER BY id DESC LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询告警事件失败: %v", err)
+	}
+	defer rows.Close()
+
+	var incidents []Incident
+	for rows.Next() {
+		incident, err := scanIncident(rows)
+		if err != nil {
+			return nil, fmt.Errorf("扫描告警事件失败: %v", err)
+		}
+		incidents = append(incidents, *incident)
+	}
+
+	// 检查迭代过程中是否有错误
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("遍历结果时出错: %v", err)
+	}
+
+	return incidents, nil
+}
+
+// AddIncidentNote 添加告警事件备注
+func AddIncidentNote(incidentID int, author, content string) error {
+	_, err := DB.Exec("INSERT INTO incident_note (incident_id, author, content) VALUES (?, ?, ?)", incidentID, author, content)
+	if err != nil {
+		return fmt.Errorf("插入告警事件备注失败: %v", err)
+	}
+	return nil
+}
+
+// GetIncidentNotes 获取告警事件的备注
+func GetIncidentNotes(incidentID int) ([]IncidentNote, error) {
+	rows, err := DB.Query("SELECT id, incident_id, author, content, created_at FROM incident_note WHERE incident_id = ? ORDER BY id", incidentID)
+	if err != nil {
+		return nil, fmt.Errorf("查询告警事件备注失败: %v", err)
+	}
+	defer rows.Close()
+
+	var notes []IncidentNote
+	for rows.Next() {
+		var note IncidentNote
+		err := rows.Scan(&note.ID, &note.IncidentID, &note.Author, &note.Content, &note.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("扫描告警事件备注失败: %v", err)
+		}
+		notes = append(notes, note)
+	}
+
+	// 检查迭代过程中是否有错误
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("遍历结果时出错: %v", err)
+	}
+
+	return notes, nil
+}
+
+// GetIncidentAlertHistory 获取告警事件关联的发送记录
+func GetIncidentAlertHistory(incidentID int) ([]AlertHistory, error) {
+	rows, err := DB.Query("SELECT id, receiver, subject, content, send_status, COALESCE(error_message, ''), COALESCE(channel, ''), incident_id, COALESCE(severity, ''), created_at FROM alert_history WHERE incident_id = ? ORDER BY id", incidentID)
+	if err != nil {
+		return nil, fmt.Errorf("查询告警消息发送历史失败: %v", err)
+	}
+	defer rows.Close()
+
+	var histories []AlertHistory
+	for rows.Next() {
+		var history AlertHistory
+		err := rows.Scan(&history.ID, &history.Receiver, &history.Subject, &history.Content, &history.SendStatus, &history.ErrorMessage, &history.Channel, &history.IncidentID, &history.Severity, &history.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("扫描告警消息发送历史失败: %v", err)
+		}
+		histories = append(histories, history)
+	}
+
+	// 检查迭代过程中是否有错误
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("遍历结果时出错: %v", err)
+	}
+
+	return histories, nil
+}
+
+// SaveIncidentProcess 保存告警事件的进程快照
+func SaveIncidentProcess(incidentID int, alertKey string, snapshot util.ProcessSnapshot) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("序列化进程快照失败: %v", err)
+	}
+
+	_, err = DB.Exec("INSERT INTO incident_process (incident_id, alert_key, snapshot) VALUES (?, ?, ?)", incidentID, alertKey, string(data))
+	if err != nil {
+		return fmt.Errorf("插入进程快照失败: %v", err)
+	}
+	return nil
+}
+
+// GetIncidentProcesses 获取告警事件的进程快照
+func GetIncidentProcesses(incidentID int) ([]IncidentProcess, error) {
+	rows, err := DB.Query("SELECT id, incident_id, alert_key, snapshot, created_at FROM incident_process WHERE incident_id = ? ORDER BY id", incidentID)
+	if err != nil {
+		return nil, fmt.Errorf("查询进程快照失败: %v", err)
+	}
+	defer rows.Close()
+
+	var processes []IncidentProcess
+	for rows.Next() {
+		var process IncidentProcess
+		var snapshot string
+		err := rows.Scan(&process.ID, &process.IncidentID, &process.AlertKey, &snapshot, &process.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("扫描进程快照失败: %v", err)
+		}
+		if err = json.Unmarshal([]byte(snapshot), &process.Snapshot); err != nil {
+			return nil, fmt.Errorf("解析进程快照失败: %v", err)
+		}
+		processes = append(processes, process)
+	}
+
+	// 检查迭代过程中是否有错误
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("遍历结果时出错: %v", err)
+	}
+
+	return processes, nil
+}
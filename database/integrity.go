@@ -0,0 +1,189 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+	"warnnotice/util"
+)
+
+const integrityWatcherColumns = "id, name, paths, COALESCE(excludes, ''), interval, COALESCE(severity, ''), enabled, baseline_at, created_at"
+
+func scanIntegrityWatcher(s scanner) (*util.IntegrityWatcher, error) {
+	var w util.IntegrityWatcher
+	var paths, excludes string
+	err := s.Scan(&w.ID, &w.Name, &paths, &excludes, &w.Interval, &w.Severity, &w.Enabled, &w.BaselineAt, &w.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal([]byte(paths), &w.Paths); err != nil {
+		return nil, fmt.Errorf("解析监控路径失败: %v", err)
+	}
+	if excludes != "" {
+		if err = json.Unmarshal([]byte(excludes), &w.Excludes); err != nil {
+			return nil, fmt.Errorf("解析排除路径失败: %v", err)
+		}
+	}
+	return &w, nil
+}
+
+// SaveIntegrityWatcher 保存文件完整性监控配置，ID为0时新增，否则更新，修改监控路径后需重新建立基线
+func SaveIntegrityWatcher(w util.IntegrityWatcher) (int, error) {
+	paths, err := json.Marshal(w.Paths)
+	if err != nil {
+		return 0, fmt.Errorf("序列化监控路径失败: %v", err)
+	}
+	excludes, err := json.Marshal(w.Excludes)
+	if err != nil {
+		return 0, fmt.Errorf("序列化排除路径失败: %v", err)
+	}
+
+	if w.ID == 0 {
+		result, err := DB.Exec("INSERT INTO integrity_watcher (name, paths, excludes, interval, severity, enabled) VALUES (?, ?, ?, ?, ?, ?)",
+			w.Name, string(paths), string(excludes), w.Interval, w.Severity, w.Enabled)
+		if err != nil {
+			return 0, fmt.Errorf("插入文件完整性监控配置失败: %v", err)
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			return 0, fmt.Errorf("获取文件完整性监控配置ID失败: %v", err)
+		}
+		return int(id), nil
+	}
+
+	result, err := DB.Exec(`UPDATE integrity_watcher SET name = ?, interval = ?, severity = ?, enabled = ?,
+		baseline_at = CASE WHEN paths = ? AND excludes = ? THEN baseline_at ELSE 0 END, paths = ?, excludes = ? WHERE id = ?`,
+		w.Name, w.Interval, w.Severity, w.Enabled, string(paths), string(excludes), string(paths), string(excludes), w.ID)
+	if err != nil {
+		return 0, fmt.Errorf("更新文件完整性监控配置失败: %v", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return 0, fmt.Errorf("文件完整性监控配置不存在: %d", w.ID)
+	}
+
+	return w.ID, nil
+}
+
+// DeleteIntegrityWatcher 删除文件完整性监控配置及其基线
+func DeleteIntegrityWatcher(id int) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("开始事务失败: %v", err)
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec("DELETE FROM integrity_watcher WHERE id = ?", id); err != nil {
+		return fmt.Errorf("删除文件完整性监控配置失败: %v", err)
+	}
+	if _, err = tx.Exec("DELETE FROM integrity_baseline WHERE watcher_id = ?", id); err != nil {
+		return fmt.Errorf("删除文件完整性基线失败: %v", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("提交事务失败: %v", err)
+	}
+	return nil
+}
+
+// GetIntegrityWatcher 获取单个文件完整性监控配置
+func GetIntegrityWatcher(id int) (*util.IntegrityWatcher, error) {
+	row := DB.QueryRow("SELECT "+integrityWatcherColumns+" FROM integrity_watcher WHERE id = ?", id)
+
+	w, err := scanIntegrityWatcher(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("查询文件完整性监控配置失败: %v", err)
+	}
+
+	return w, nil
+}
+
+// GetIntegrityWatchers 获取文件完整性监控配置，enabledOnly为true时只返回已启用的配置
+func GetIntegrityWatchers(enabledOnly bool) ([]util.IntegrityWatcher, error) {
+	query := "SELECT " + integrityWatcherColumns + " FROM integrity_watcher"
+	if enabledOnly {
+		query += " WHERE enabled = 1"
+	}
+	rows, err := DB.Query(query + " ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("查询文件完整性监控配置失败: %v", err)
+	}
+	defer rows.Close()
+
+	var watchers []util.IntegrityWatcher
+	for rows.Next() {
+		w, err := scanIntegrityWatcher(rows)
+		if err != nil {
+			return nil, fmt.Errorf("扫描文件完整性监控配置失败: %v", err)
+		}
+		watchers = append(watchers, *w)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("遍历结果时出错: %v", err)
+	}
+
+	return watchers, nil
+}
+
+// SaveIntegrityBaseline 用当前文件状态替换基线，返回基线确认时间
+func SaveIntegrityBaseline(watcherID int, states map[string]util.FileState) (int64, error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("开始事务失败: %v", err)
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec("DELETE FROM integrity_baseline WHERE watcher_id = ?", watcherID); err != nil {
+		return 0, fmt.Errorf("删除文件完整性基线失败: %v", err)
+	}
+
+	stmt, err := tx.Prepare("INSERT INTO integrity_baseline (watcher_id, path, hash, size, mode, uid, gid) VALUES (?, ?, ?, ?, ?, ?, ?)")
+	if err != nil {
+		return 0, fmt.Errorf("准备SQL语句失败: %v", err)
+	}
+	defer stmt.Close()
+
+	for _, s := range states {
+		if _, err = stmt.Exec(watcherID, s.Path, s.Hash, s.Size, s.Mode, s.UID, s.GID); err != nil {
+			return 0, fmt.Errorf("保存文件完整性基线失败: %v", err)
+		}
+	}
+
+	now := time.Now().Unix()
+	if _, err = tx.Exec("UPDATE integrity_watcher SET baseline_at = ? WHERE id = ?", now, watcherID); err != nil {
+		return 0, fmt.Errorf("更新基线确认时间失败: %v", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("提交事务失败: %v", err)
+	}
+	return now, nil
+}
+
+// GetIntegrityBaseline 获取文件完整性基线
+func GetIntegrityBaseline(watcherID int) (map[string]util.FileState, error) {
+	rows, err := DB.Query("SELECT path, hash, size, mode, uid, gid FROM integrity_baseline WHERE watcher_id = ?", watcherID)
+	if err != nil {
+		return nil, fmt.Errorf("查询文件完整性基线失败: %v", err)
+	}
+	defer rows.Close()
+
+	states := make(map[string]util.FileState)
+	for rows.Next() {
+		var s util.FileState
+		if err = rows.Scan(&s.Path, &s.Hash, &s.Size, &s.Mode, &s.UID, &s.GID); err != nil {
+			return nil, fmt.Errorf("扫描文件完整性基线失败: %v", err)
+		}
+		states[s.Path] = s
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("遍历结果时出错: %v", err)
+	}
+
+	return states, nil
+}
@@ -0,0 +1,141 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+	"warnnotice/util"
+)
+
+const logWatcherColumns = "id, name, path, rules, enabled, created_at"
+
+func scanLogWatcher(s scanner) (*util.LogWatcher, error) {
+	var w util.LogWatcher
+	var rules string
+	err := s.Scan(&w.ID, &w.Name, &w.Path, &rules, &w.Enabled, &w.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal([]byte(rules), &w.Rules); err != nil {
+		return nil, fmt.Errorf("解析日志匹配规则失败: %v", err)
+	}
+	return &w, nil
+}
+
+// SaveLogWatcher 保存日志监控配置，ID为0时新增，否则更新
+func SaveLogWatcher(w util.LogWatcher) (int, error) {
+	rules, err := json.Marshal(w.Rules)
+	if err != nil {
+		return 0, fmt.Errorf("序列化日志匹配规则失败: %v", err)
+	}
+
+	if w.ID == 0 {
+		result, err := DB.Exec("INSERT INTO log_watcher (name, path, rules, enabled) VALUES (?, ?, ?, ?)",
+			w.Name, w.Path, string(rules), w.Enabled)
+		if err != nil {
+			return 0, fmt.Errorf("插入日志监控配置失败: %v", err)
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			return 0, fmt.Errorf("获取日志监控配置ID失败: %v", err)
+		}
+		return int(id), nil
+	}
+
+	result, err := DB.Exec("UPDATE log_watcher SET name = ?, path = ?, rules = ?, enabled = ? WHERE id = ?",
+		w.Name, w.Path, string(rules), w.Enabled, w.ID)
+	if err != nil {
+		return 0, fmt.Errorf("更新日志监控配置失败: %v", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return 0, fmt.Errorf("日志监控配置不存在: %d", w.ID)
+	}
+
+	return w.ID, nil
+}
+
+// DeleteLogWatcher 删除日志监控配置及其读取位置
+func DeleteLogWatcher(id int) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("开始事务失败: %v", err)
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec("DELETE FROM log_watcher WHERE id = ?", id); err != nil {
+		return fmt.Errorf("删除日志监控配置失败: %v", err)
+	}
+	if _, err = tx.Exec("DELETE FROM log_offset WHERE watcher_id = ?", id); err != nil {
+		return fmt.Errorf("删除日志读取位置失败: %v", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("提交事务失败: %v", err)
+	}
+	return nil
+}
+
+// GetLogWatchers 获取日志监控配置，enabledOnly为true时只返回已启用的配置
+func GetLogWatchers(enabledOnly bool) ([]util.LogWatcher, error) {
+	query := "SELECT " + logWatcherColumns + " FROM log_watcher"
+	if enabledOnly {
+		query += " WHERE enabled = 1"
+	}
+	rows, err := DB.Query(query + " ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("查询日志监控配置失败: %v", err)
+	}
+	defer rows.Close()
+
+	var watchers []util.LogWatcher
+	for rows.Next() {
+		w, err := scanLogWatcher(rows)
+		if err != nil {
+			return nil, fmt.Errorf("扫描日志监控配置失败: %v", err)
+		}
+		watchers = append(watchers, *w)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("遍历结果时出错: %v", err)
+	}
+
+	return watchers, nil
+}
+
+// SaveLogOffset 保存日志文件读取位置
+func SaveLogOffset(o util.LogOffset) error {
+	_, err := DB.Exec(`INSERT INTO log_offset (watcher_id, path, inode, offset, updated_at) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(watcher_id) DO UPDATE SET path = excluded.path, inode = excluded.inode, offset = excluded.offset, updated_at = excluded.updated_at`,
+		o.WatcherID, o.Path, int64(o.Inode), o.Offset, time.Now().Unix())
+	if err != nil {
+		return fmt.Errorf("保存日志读取位置失败: %v", err)
+	}
+	return nil
+}
+
+// GetLogOffsets 获取所有日志文件读取位置
+func GetLogOffsets() ([]util.LogOffset, error) {
+	rows, err := DB.Query("SELECT watcher_id, path, inode, offset, updated_at FROM log_offset")
+	if err != nil {
+		return nil, fmt.Errorf("查询日志读取位置失败: %v", err)
+	}
+	defer rows.Close()
+
+	var offsets []util.LogOffset
+	for rows.Next() {
+		var o util.LogOffset
+		var inode int64
+		if err = rows.Scan(&o.WatcherID, &o.Path, &inode, &o.Offset, &o.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("扫描日志读取位置失败: %v", err)
+		}
+		o.Inode = uint64(inode)
+		offsets = append(offsets, o)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("遍历结果时出错: %v", err)
+	}
+
+	return offsets, nil
+}
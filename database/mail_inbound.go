@@ -0,0 +1,56 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"warnnotice/util"
+)
+
+// SaveMailInboundConfig 保存邮件接收配置
+func SaveMailInboundConfig(config util.MailInboundConfig) error {
+	recipients, err := json.Marshal(config.Recipients)
+	if err != nil {
+		return fmt.Errorf("序列化收件人地址失败: %v", err)
+	}
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("开始事务失败: %v", err)
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec("DELETE FROM mail_inbound_config"); err != nil {
+		return fmt.Errorf("删除旧邮件接收配置失败: %v", err)
+	}
+	_, err = tx.Exec("INSERT INTO mail_inbound_config (enabled, address, recipients, severity, max_size_kb) VALUES (?, ?, ?, ?, ?)",
+		config.Enabled, config.Address, string(recipients), config.Severity, config.MaxSizeKB)
+	if err != nil {
+		return fmt.Errorf("插入邮件接收配置失败: %v", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("提交事务失败: %v", err)
+	}
+	return nil
+}
+
+// GetMailInboundConfig 获取邮件接收配置
+func GetMailInboundConfig() (*util.MailInboundConfig, error) {
+	row := DB.QueryRow("SELECT enabled, address, recipients, severity, max_size_kb FROM mail_inbound_config ORDER BY id DESC LIMIT 1")
+
+	var config util.MailInboundConfig
+	var recipients string
+	err := row.Scan(&config.Enabled, &config.Address, &recipients, &config.Severity, &config.MaxSizeKB)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // 没有配置
+		}
+		return nil, fmt.Errorf("查询邮件接收配置失败: %v", err)
+	}
+	if err = json.Unmarshal([]byte(recipients), &config.Recipients); err != nil {
+		return nil, fmt.Errorf("解析收件人地址失败: %v", err)
+	}
+
+	return &config, nil
+}
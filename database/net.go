@@ -0,0 +1,73 @@
+package database
+
+import (
+	"fmt"
+	"warnnotice/util"
+)
+
+// SaveNetStatus 保存各网卡的状态
+func SaveNetStatus(timestamp int64, stats map[string]util.NetStat) error {
+	if len(stats) == 0 {
+		return nil
+	}
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("开始事务失败: %v", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`INSERT INTO net_status (timestamp, interface, rx_bytes_per_sec, tx_bytes_per_sec, rx_errors_per_sec, tx_errors_per_sec,
+		rx_drops_per_sec, tx_drops_per_sec) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("准备插入语句失败: %v", err)
+	}
+	defer stmt.Close()
+
+	for name, stat := range stats {
+		_, err = stmt.Exec(timestamp, name, stat.RxBytesPerSec, stat.TxBytesPerSec, stat.RxErrorsPerSec, stat.TxErrorsPerSec,
+			stat.RxDropsPerSec, stat.TxDropsPerSec)
+		if err != nil {
+			return fmt.Errorf("插入网卡状态失败: %v", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("提交事务失败: %v", err)
+	}
+
+	return nil
+}
+
+// GetNetStatusSince 获取指定时间之后的网卡状态，按时间戳和网卡分组
+func GetNetStatusSince(since int64) (map[int64]map[string]util.NetStat, error) {
+	rows, err := DB.Query(`SELECT timestamp, interface, rx_bytes_per_sec, tx_bytes_per_sec, rx_errors_per_sec, tx_errors_per_sec,
+		rx_drops_per_sec, tx_drops_per_sec FROM net_status WHERE timestamp >= ?`, since)
+	if err != nil {
+		return nil, fmt.Errorf("查询网卡状态失败: %v", err)
+	}
+	defer rows.Close()
+
+	result := make(map[int64]map[string]util.NetStat)
+	for rows.Next() {
+		var timestamp int64
+		var name string
+		var stat util.NetStat
+		err := rows.Scan(&timestamp, &name, &stat.RxBytesPerSec, &stat.TxBytesPerSec, &stat.RxErrorsPerSec, &stat.TxErrorsPerSec,
+			&stat.RxDropsPerSec, &stat.TxDropsPerSec)
+		if err != nil {
+			return nil, fmt.Errorf("扫描网卡状态失败: %v", err)
+		}
+		if result[timestamp] == nil {
+			result[timestamp] = make(map[string]util.NetStat)
+		}
+		result[timestamp][name] = stat
+	}
+
+	// 检查迭代过程中是否有错误
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("遍历结果时出错: %v", err)
+	}
+
+	return result, nil
+}
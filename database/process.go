@@ -0,0 +1,86 @@
+package database
+
+import (
+	"fmt"
+	"warnnotice/util"
+)
+
+const processWatcherColumns = "id, name, match_type, pattern, min_count, max_count, COALESCE(severity, ''), cpu_threshold, rss_threshold_mb, " +
+	"open_files_threshold, enabled, created_at"
+
+func scanProcessWatcher(s scanner) (*util.ProcessWatcher, error) {
+	var w util.ProcessWatcher
+	err := s.Scan(&w.ID, &w.Name, &w.MatchType, &w.Pattern, &w.MinCount, &w.MaxCount, &w.Severity, &w.CPUThreshold, &w.RSSThresholdMB,
+		&w.OpenFilesThreshold, &w.Enabled, &w.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+// SaveProcessWatcher 保存进程监控配置，ID为0时新增，否则更新
+func SaveProcessWatcher(w util.ProcessWatcher) (int, error) {
+	if w.ID == 0 {
+		result, err := DB.Exec(`INSERT INTO process_watcher (name, match_type, pattern, min_count, max_count, severity, cpu_threshold, rss_threshold_mb,
+			open_files_threshold, enabled) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			w.Name, w.MatchType, w.Pattern, w.MinCount, w.MaxCount, w.Severity, w.CPUThreshold, w.RSSThresholdMB, w.OpenFilesThreshold, w.Enabled)
+		if err != nil {
+			return 0, fmt.Errorf("插入进程监控配置失败: %v", err)
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			return 0, fmt.Errorf("获取进程监控配置ID失败: %v", err)
+		}
+		return int(id), nil
+	}
+
+	result, err := DB.Exec(`UPDATE process_watcher SET name = ?, match_type = ?, pattern = ?, min_count = ?, max_count = ?, severity = ?,
+		cpu_threshold = ?, rss_threshold_mb = ?, open_files_threshold = ?, enabled = ? WHERE id = ?`,
+		w.Name, w.MatchType, w.Pattern, w.MinCount, w.MaxCount, w.Severity, w.CPUThreshold, w.RSSThresholdMB, w.OpenFilesThreshold, w.Enabled, w.ID)
+	if err != nil {
+		return 0, fmt.Errorf("更新进程监控配置失败: %v", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return 0, fmt.Errorf("进程监控配置不存在: %d", w.ID)
+	}
+
+	return w.ID, nil
+}
+
+// DeleteProcessWatcher 删除进程监控配置
+func DeleteProcessWatcher(id int) error {
+	_, err := DB.Exec("DELETE FROM process_watcher WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("删除进程监控配置失败: %v", err)
+	}
+	return nil
+}
+
+// GetProcessWatchers 获取进程监控配置，enabledOnly为true时只返回已启用的配置
+func GetProcessWatchers(enabledOnly bool) ([]util.ProcessWatcher, error) {
+	query := "SELECT " + processWatcherColumns + " FROM process_watcher"
+	if enabledOnly {
+		query += " WHERE enabled = 1"
+	}
+	rows, err := DB.Query(query + " ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("查询进程监控配置失败: %v", err)
+	}
+	defer rows.Close()
+
+	var watchers []util.ProcessWatcher
+	for rows.Next() {
+		w, err := scanProcessWatcher(rows)
+		if err != nil {
+			return nil, fmt.Errorf("扫描进程监控配置失败: %v", err)
+		}
+		watchers = append(watchers, *w)
+	}
+
+	// 检查迭代过程中是否有错误
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("遍历结果时出错: %v", err)
+	}
+
+	return watchers, nil
+}
@@ -0,0 +1,266 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"warnnotice/util"
+)
+
+const scriptColumns = "id, name, path, parameters, timeout, interval, COALESCE(mode, ''), returns, COALESCE(schedule, ''), enabled, created_at"
+
+func scanScript(s scanner) (*util.Script, error) {
+	var sc util.Script
+	var returns, schedule string
+	err := s.Scan(&sc.ID, &sc.Name, &sc.Path, &sc.Parameters, &sc.Timeout, &sc.Interval, &sc.Mode, &returns, &schedule, &sc.Enabled, &sc.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	sc.Returns = make(map[int]string)
+	if returns != "" {
+		if err = json.Unmarshal([]byte(returns), &sc.Returns); err != nil {
+			return nil, fmt.Errorf("解析脚本返回值配置失败: %v", err)
+		}
+	}
+	if schedule != "" {
+		if err = json.Unmarshal([]byte(schedule), &sc.Schedule); err != nil {
+			return nil, fmt.Errorf("解析定时执行配置失败: %v", err)
+		}
+	}
+	return &sc, nil
+}
+
+// SaveScript 保存脚本配置，ID为0时新增，否则更新
+func SaveScript(s util.Script) (int, error) {
+	return saveScript(DB, s)
+}
+
+// execer 数据库或事务
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+func saveScript(db execer, s util.Script) (int, error) {
+	returns, err := json.Marshal(s.Returns)
+	if err != nil {
+		return 0, fmt.Errorf("序列化脚本返回值配置失败: %v", err)
+	}
+	schedule, err := json.Marshal(s.Schedule)
+	if err != nil {
+		return 0, fmt.Errorf("序列化定时执行配置失败: %v", err)
+	}
+
+	if s.ID == 0 {
+		result, err := db.Exec("INSERT INTO script (name, path, parameters, timeout, interval, mode, returns, schedule, enabled) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+			s.Name, s.Path, s.Parameters, s.Timeout, s.Interval, s.Mode, string(returns), string(schedule), s.Enabled)
+		if err != nil {
+			return 0, fmt.Errorf("插入脚本配置失败: %v", err)
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			return 0, fmt.Errorf("获取脚本配置ID失败: %v", err)
+		}
+		return int(id), nil
+	}
+
+	result, err := db.Exec("UPDATE script SET name = ?, path = ?, parameters = ?, timeout = ?, interval = ?, mode = ?, returns = ?, schedule = ?, enabled = ? WHERE id = ?",
+		s.Name, s.Path, s.Parameters, s.Timeout, s.Interval, s.Mode, string(returns), string(schedule), s.Enabled, s.ID)
+	if err != nil {
+		return 0, fmt.Errorf("更新脚本配置失败: %v", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return 0, fmt.Errorf("脚本配置不存在: %d", s.ID)
+	}
+
+	return s.ID, nil
+}
+
+// DeleteScript 删除脚本配置及其执行历史
+func DeleteScript(id int) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("开始事务失败: %v", err)
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec("DELETE FROM script WHERE id = ?", id); err != nil {
+		return fmt.Errorf("删除脚本配置失败: %v", err)
+	}
+	if _, err = tx.Exec("DELETE FROM script_history WHERE script_id = ?", id); err != nil {
+		return fmt.Errorf("删除脚本执行历史失败: %v", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("提交事务失败: %v", err)
+	}
+	return nil
+}
+
+// GetScript 根据ID获取脚本配置，不存在时返回nil
+func GetScript(id int) (*util.Script, error) {
+	return getScript("id = ?", id)
+}
+
+// GetScriptByName 根据名称获取脚本配置，不存在时返回nil
+func GetScriptByName(name string) (*util.Script, error) {
+	return getScript("name = ?", name)
+}
+
+func getScript(where string, arg interface{}) (*util.Script, error) {
+	row := DB.QueryRow("SELECT "+scriptColumns+" FROM script WHERE "+where, arg)
+
+	s, err := scanScript(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("查询脚本配置失败: %v", err)
+	}
+
+	return s, nil
+}
+
+// GetScripts 获取脚本配置，enabledOnly为true时只返回已启用的脚本
+func GetScripts(enabledOnly bool) ([]util.Script, error) {
+	query := "SELECT " + scriptColumns + " FROM script"
+	if enabledOnly {
+		query += " WHERE enabled = 1"
+	}
+	rows, err := DB.Query(query + " ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("查询脚本配置失败: %v", err)
+	}
+	defer rows.Close()
+
+	var scripts []util.Script
+	for rows.Next() {
+		s, err := scanScript(rows)
+		if err != nil {
+			return nil, fmt.Errorf("扫描脚本配置失败: %v", err)
+		}
+		scripts = append(scripts, *s)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("遍历结果时出错: %v", err)
+	}
+
+	return scripts, nil
+}
+
+// ScriptMetricPoint 性能数据的一次取值
+type ScriptMetricPoint struct {
+	Value     float64 `json:"value"`
+	CreatedAt string  `json:"created_at"`
+}
+
+// ScriptMetricSeries 脚本单个性能指标的历史数据，按时间升序排列
+type ScriptMetricSeries struct {
+	Label  string              `json:"label"`
+	UOM    string              `json:"uom"`
+	Points []ScriptMetricPoint `json:"points"`
+}
+
+// GetScriptMetrics 获取脚本最近limit次执行的性能数据，按指标分组，label不为空时只返回该指标
+func GetScriptMetrics(scriptID, limit int, label string) ([]ScriptMetricSeries, error) {
+	rows, err := DB.Query(`SELECT metrics, created_at FROM (
+			SELECT id, metrics, created_at FROM script_history WHERE script_id = ? AND metrics != '' ORDER BY id DESC LIMIT ?
+		) ORDER BY id`, scriptID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("查询脚本性能数据失败: %v", err)
+	}
+	defer rows.Close()
+
+	series := []ScriptMetricSeries{}
+	index := make(map[string]int)
+	for rows.Next() {
+		var metricsJSON, createdAt string
+		if err = rows.Scan(&metricsJSON, &createdAt); err != nil {
+			return nil, fmt.Errorf("扫描脚本性能数据失败: %v", err)
+		}
+		var metrics []util.PerfData
+		if err = json.Unmarshal([]byte(metricsJSON), &metrics); err != nil {
+			return nil, fmt.Errorf("解析性能数据失败: %v", err)
+		}
+
+		for _, m := range metrics {
+			if label != "" && m.Label != label {
+				continue
+			}
+			i, exists := index[m.Label]
+			if !exists {
+				i = len(series)
+				index[m.Label] = i
+				series = append(series, ScriptMetricSeries{Label: m.Label})
+			}
+			// 单位以最新一次为准
+			series[i].UOM = m.UOM
+			series[i].Points = append(series[i].Points, ScriptMetricPoint{Value: m.Value, CreatedAt: createdAt})
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("遍历结果时出错: %v", err)
+	}
+
+	return series, nil
+}
+
+// migrateScripts 将旧版本script_config和script_return_config中的脚本迁移为名为default的脚本
+func migrateScripts() error {
+	var config util.ScriptConfig
+	err := DB.QueryRow("SELECT path, COALESCE(parameters, ''), COALESCE(timeout, 0), COALESCE(interval, 0) FROM script_config ORDER BY id DESC LIMIT 1").
+		Scan(&config.Path, &config.Parameters, &config.Timeout, &config.Interval)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("查询旧脚本配置失败: %v", err)
+	}
+
+	rows, err := DB.Query("SELECT return_value, alert_text FROM script_return_config")
+	if err != nil {
+		return fmt.Errorf("查询旧脚本返回值配置失败: %v", err)
+	}
+	returns := make(map[int]string)
+	for rows.Next() {
+		var value int
+		var text string
+		if err = rows.Scan(&value, &text); err != nil {
+			rows.Close()
+			return fmt.Errorf("扫描旧脚本返回值配置失败: %v", err)
+		}
+		returns[value] = text
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("遍历结果时出错: %v", err)
+	}
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("开始事务失败: %v", err)
+	}
+	defer tx.Rollback()
+
+	s := util.Script{Name: util.DefaultScriptName, ScriptConfig: config, Returns: returns, Enabled: true}
+	id, err := saveScript(tx, s)
+	if err != nil {
+		return err
+	}
+	if _, err = tx.Exec("UPDATE script_history SET script_id = ? WHERE script_id = 0", id); err != nil {
+		return fmt.Errorf("更新脚本执行历史失败: %v", err)
+	}
+	// 迁移后删除旧配置，避免删除脚本后再次迁移
+	if _, err = tx.Exec("DELETE FROM script_config"); err != nil {
+		return fmt.Errorf("删除旧脚本配置失败: %v", err)
+	}
+	if _, err = tx.Exec("DELETE FROM script_return_config"); err != nil {
+		return fmt.Errorf("删除旧脚本返回值配置失败: %v", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("提交事务失败: %v", err)
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package database
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -43,6 +44,18 @@ func InitDB() error {
 		return fmt.Errorf("创建表失败: %v", err)
 	}
 
+	// 升级旧版本表结构
+	err = migrateTables()
+	if err != nil {
+		return fmt.Errorf("升级表结构失败: %v", err)
+	}
+
+	// 迁移旧版本的脚本配置
+	err = migrateScripts()
+	if err != nil {
+		return fmt.Errorf("迁移脚本配置失败: %v", err)
+	}
+
 	// 初始化系统名称
 	err = initSystemName()
 	if err != nil {
@@ -72,6 +85,7 @@ func createTables() error {
 		password TEXT NOT NULL,
 		from_email TEXT NOT NULL,
 		to_email TEXT NOT NULL,
+		min_severity TEXT DEFAULT '',  -- 最低告警级别，为空时接收所有级别
 		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 	);`
 
@@ -105,6 +119,27 @@ func createTables() error {
 		cpu_threshold REAL NOT NULL,
 		mem_threshold REAL NOT NULL,
 		disk_threshold REAL NOT NULL,
+		repeat_interval INTEGER DEFAULT 60,
+		cpu_critical_threshold REAL DEFAULT 0,
+		mem_critical_threshold REAL DEFAULT 0,
+		disk_critical_threshold REAL DEFAULT 0,
+		disk_min_free_gb REAL DEFAULT 0,
+		disk_critical_min_free_gb REAL DEFAULT 0,
+		inode_threshold REAL DEFAULT 0,
+		inode_critical_threshold REAL DEFAULT 0,
+		load_threshold REAL DEFAULT 0,
+		load_critical_threshold REAL DEFAULT 0,
+		swap_threshold REAL DEFAULT 0,
+		swap_critical_threshold REAL DEFAULT 0,
+		net_rx_threshold REAL DEFAULT 0,
+		net_tx_threshold REAL DEFAULT 0,
+		net_error_threshold REAL DEFAULT 0,
+		net_rx_critical_threshold REAL DEFAULT 0,
+		net_tx_critical_threshold REAL DEFAULT 0,
+		net_error_critical_threshold REAL DEFAULT 0,
+		process_top_n INTEGER DEFAULT 5,
+		disk_filter TEXT DEFAULT '',
+		disk_rules TEXT DEFAULT '',
 		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 	);`
 
@@ -115,16 +150,55 @@ func createTables() error {
 		cpu_usage REAL NOT NULL,
 		mem_usage REAL NOT NULL,
 		disk_usage REAL NOT NULL,
+		load1 REAL DEFAULT 0,
+		load5 REAL DEFAULT 0,
+		load15 REAL DEFAULT 0,
+		swap_usage REAL DEFAULT 0,
 		timestamp INTEGER NOT NULL,
 		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 	);`
 
+	// 磁盘分区状态历史表，与system_status通过timestamp关联
+	diskStatusSQL := `
+	CREATE TABLE IF NOT EXISTS disk_status (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		timestamp INTEGER NOT NULL,
+		mountpoint TEXT NOT NULL,
+		fstype TEXT DEFAULT '',
+		total INTEGER NOT NULL,
+		free INTEGER NOT NULL,
+		used_percent REAL NOT NULL,
+		inodes_total INTEGER DEFAULT 0,
+		inodes_free INTEGER DEFAULT 0,
+		inodes_used_percent REAL DEFAULT 0,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_disk_status_timestamp ON disk_status(timestamp);`
+
+	// 网卡状态历史表，与system_status通过timestamp关联
+	netStatusSQL := `
+	CREATE TABLE IF NOT EXISTS net_status (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		timestamp INTEGER NOT NULL,
+		interface TEXT NOT NULL,
+		rx_bytes_per_sec REAL DEFAULT 0,
+		tx_bytes_per_sec REAL DEFAULT 0,
+		rx_errors_per_sec REAL DEFAULT 0,
+		tx_errors_per_sec REAL DEFAULT 0,
+		rx_drops_per_sec REAL DEFAULT 0,
+		tx_drops_per_sec REAL DEFAULT 0,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_net_status_timestamp ON net_status(timestamp);`
+
 	// 脚本执行历史表
 	scriptHistorySQL := `
 	CREATE TABLE IF NOT EXISTS script_history (
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		script_id INTEGER DEFAULT 0,
 		result INTEGER NOT NULL,
 		output TEXT,
+		metrics TEXT DEFAULT '',       -- Nagios插件性能数据(JSON)
 		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 	);`
 	// 新增告警消息发送记录表
@@ -136,9 +210,298 @@ func createTables() error {
 		content TEXT NOT NULL,
 		send_status BOOLEAN NOT NULL,  -- true: 成功, false: 失败
 		error_message TEXT,            -- 错误信息，如果发送失败
+		channel TEXT DEFAULT '',       -- 通知渠道名称
+		incident_id INTEGER DEFAULT 0, -- 关联的告警事件
+		severity TEXT DEFAULT '',      -- 告警级别
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);`
+
+	// 通知渠道配置表
+	notifyChannelSQL := `
+	CREATE TABLE IF NOT EXISTS notify_channel (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		type TEXT NOT NULL,
+		config TEXT NOT NULL,          -- 渠道配置(JSON)
+		enabled BOOLEAN NOT NULL DEFAULT 1,
+		min_severity TEXT DEFAULT '',  -- 最低告警级别，为空时接收所有级别
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(name)
+	);`
+
+	// 告警事件表，记录每次告警的开始和恢复时间
+	incidentsSQL := `
+	CREATE TABLE IF NOT EXISTS incidents (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		alert_key TEXT NOT NULL,       -- 告警条件标识
+		source TEXT NOT NULL,
+		title TEXT NOT NULL,
+		severity TEXT NOT NULL,
+		status TEXT NOT NULL,          -- firing: 告警中, acknowledged: 已确认, resolved: 已恢复
+		message TEXT,
+		started_at INTEGER NOT NULL,   -- 开始时间戳
+		resolved_at INTEGER DEFAULT 0, -- 恢复时间戳
+		resolved_by TEXT DEFAULT '',   -- 手动关闭人，自动恢复时为空
+		acknowledged_by TEXT DEFAULT '',
+		acknowledged_at INTEGER DEFAULT 0,
+		notify_count INTEGER DEFAULT 0, -- 通知次数
+		last_notified_at INTEGER DEFAULT 0,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);`
+
+	// 告警事件备注表
+	incidentNoteSQL := `
+	CREATE TABLE IF NOT EXISTS incident_note (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		incident_id INTEGER NOT NULL,
+		author TEXT NOT NULL,
+		content TEXT NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);`
+
+	// 告警事件进程快照表
+	incidentProcessSQL := `
+	CREATE TABLE IF NOT EXISTS incident_process (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		incident_id INTEGER NOT NULL,
+		alert_key TEXT NOT NULL,
+		snapshot TEXT NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);`
+
+	// 进程监控配置表
+	processWatcherSQL := `
+	CREATE TABLE IF NOT EXISTS process_watcher (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		match_type TEXT NOT NULL,      -- name: 进程名, cmdline: 命令行正则, pidfile: pid文件
+		pattern TEXT NOT NULL,
+		min_count INTEGER DEFAULT 1,
+		max_count INTEGER DEFAULT 0,   -- 0表示不限制
+		severity TEXT DEFAULT '',
+		cpu_threshold REAL DEFAULT 0,
+		rss_threshold_mb REAL DEFAULT 0,
+		open_files_threshold INTEGER DEFAULT 0,
+		enabled BOOLEAN NOT NULL DEFAULT 1,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(name)
+	);`
+
+	// 健康检查配置表
+	healthCheckSQL := `
+	CREATE TABLE IF NOT EXISTS health_check (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		type TEXT NOT NULL,            -- tcp, http
+		target TEXT NOT NULL,          -- host:port 或 URL
+		timeout INTEGER DEFAULT 10,    -- 超时时间(秒)
+		interval INTEGER DEFAULT 60,   -- 检查间隔(秒)
+		failure_threshold INTEGER DEFAULT 3,
+		severity TEXT DEFAULT '',
+		enabled BOOLEAN NOT NULL DEFAULT 1,
+		http_config TEXT DEFAULT '',   -- HTTP检查配置(JSON)
+		tls_config TEXT DEFAULT '',    -- 证书有效期检查配置(JSON)
+		file_config TEXT DEFAULT '',   -- 文件检查配置(JSON)
+		schedule TEXT DEFAULT '',      -- 定时执行配置(JSON)
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(name)
+	);`
+
+	// 健康检查历史表
+	checkHistorySQL := `
+	CREATE TABLE IF NOT EXISTS check_history (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		check_id INTEGER NOT NULL,
+		success BOOLEAN NOT NULL,
+		latency INTEGER DEFAULT 0,     -- 响应时间(毫秒)
+		status_code INTEGER DEFAULT 0,
+		message TEXT,
+		value REAL DEFAULT 0,          -- 指标值，如文件更新时间、目录大小
+		timestamp INTEGER NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_check_history_check_id ON check_history(check_id);`
+
+	// 健康检查最近一次获取到的证书
+	certificateSQL := `
+	CREATE TABLE IF NOT EXISTS certificate (
+		check_id INTEGER PRIMARY KEY,
+		subject TEXT,
+		issuer TEXT,
+		sans TEXT,                     -- 证书包含的域名和IP(JSON)
+		serial_number TEXT,
+		not_before INTEGER NOT NULL,
+		not_after INTEGER NOT NULL,
+		updated_at INTEGER NOT NULL
+	);`
+	// 日志监控配置表
+	logWatcherSQL := `
+	CREATE TABLE IF NOT EXISTS log_watcher (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		path TEXT NOT NULL,
+		rules TEXT NOT NULL,           -- 匹配规则(JSON)
+		enabled BOOLEAN NOT NULL DEFAULT 1,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(name)
+	);`
+
+	// 日志文件读取位置表
+	logOffsetSQL := `
+	CREATE TABLE IF NOT EXISTS log_offset (
+		watcher_id INTEGER PRIMARY KEY,
+		path TEXT NOT NULL,
+		inode INTEGER DEFAULT 0,
+		offset INTEGER DEFAULT 0,
+		updated_at INTEGER NOT NULL
+	);`
+
+	// 文件完整性监控配置表
+	integrityWatcherSQL := `
+	CREATE TABLE IF NOT EXISTS integrity_watcher (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		paths TEXT NOT NULL,           -- 监控路径(JSON)
+		excludes TEXT DEFAULT '',      -- 排除路径(JSON)
+		interval INTEGER DEFAULT 10,   -- 扫描间隔(分钟)
+		severity TEXT DEFAULT '',
+		enabled BOOLEAN NOT NULL DEFAULT 1,
+		baseline_at INTEGER DEFAULT 0, -- 基线确认时间
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(name)
+	);`
+
+	// 文件完整性基线表
+	integrityBaselineSQL := `
+	CREATE TABLE IF NOT EXISTS integrity_baseline (
+		watcher_id INTEGER NOT NULL,
+		path TEXT NOT NULL,
+		hash TEXT DEFAULT '',
+		size INTEGER DEFAULT 0,
+		mode TEXT DEFAULT '',
+		uid INTEGER DEFAULT 0,
+		gid INTEGER DEFAULT 0,
+		PRIMARY KEY (watcher_id, path)
+	);`
+
+	// 心跳监控配置表
+	heartbeatSQL := `
+	CREATE TABLE IF NOT EXISTS heartbeat (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		token TEXT NOT NULL,
+		period INTEGER NOT NULL,       -- 预期的ping间隔(秒)
+		grace INTEGER DEFAULT 0,       -- 宽限时间(秒)
+		severity TEXT DEFAULT '',
+		enabled BOOLEAN NOT NULL DEFAULT 1,
+		last_ping_at INTEGER DEFAULT 0,
+		last_ping_kind TEXT DEFAULT '',
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(name),
+		UNIQUE(token)
+	);`
+
+	// 心跳ping记录表
+	heartbeatPingSQL := `
+	CREATE TABLE IF NOT EXISTS heartbeat_ping (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		heartbeat_id INTEGER NOT NULL,
+		kind TEXT NOT NULL,            -- start, success, fail
+		body TEXT,
+		remote_addr TEXT,
+		timestamp INTEGER NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_heartbeat_ping_heartbeat_id ON heartbeat_ping(heartbeat_id);`
+
+	// 外部告警API密钥表
+	apiKeySQL := `
+	CREATE TABLE IF NOT EXISTS api_key (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		source TEXT NOT NULL,          -- 告警来源，同一来源可有多个密钥
+		key_hash TEXT NOT NULL,        -- 密钥的SHA-256哈希
+		prefix TEXT NOT NULL,
+		enabled BOOLEAN NOT NULL DEFAULT 1,
+		last_used_at INTEGER DEFAULT 0,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(key_hash)
+	);`
+
+	// syslog接收配置表
+	syslogConfigSQL := `
+	CREATE TABLE IF NOT EXISTS syslog_config (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		enabled BOOLEAN NOT NULL DEFAULT 0,
+		protocol TEXT NOT NULL,        -- udp, tcp, both
+		address TEXT NOT NULL,
+		max_messages INTEGER NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);`
+
+	// syslog匹配规则表
+	syslogRuleSQL := `
+	CREATE TABLE IF NOT EXISTS syslog_rule (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		facilities TEXT DEFAULT '',    -- 设施列表(JSON)
+		min_severity TEXT DEFAULT '',
+		hostname TEXT DEFAULT '',
+		pattern TEXT DEFAULT '',
+		threshold INTEGER NOT NULL,
+		window INTEGER NOT NULL,
+		severity TEXT DEFAULT '',
+		enabled BOOLEAN NOT NULL DEFAULT 1,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(name)
+	);`
+
+	// 最近收到的syslog消息表，只保留配置的条数
+	syslogMessageSQL := `
+	CREATE TABLE IF NOT EXISTS syslog_message (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		timestamp INTEGER NOT NULL,
+		received_at INTEGER NOT NULL,
+		remote_addr TEXT,
+		facility TEXT,
+		severity TEXT,
+		hostname TEXT,
+		app_name TEXT,
+		message TEXT,
+		rules TEXT DEFAULT ''          -- 匹配的规则名称
+	);`
+
+	// 邮件接收配置表
+	mailInboundConfigSQL := `
+	CREATE TABLE IF NOT EXISTS mail_inbound_config (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		enabled BOOLEAN NOT NULL DEFAULT 0,
+		address TEXT NOT NULL,
+		recipients TEXT NOT NULL,      -- 接收的收件人地址(JSON)
+		severity TEXT DEFAULT '',
+		max_size_kb INTEGER NOT NULL,
 		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 	);`
-	tables := []string{systemConfigSQL, emailConfigSQL, scriptConfigSQL, scriptReturnConfigSQL, monitorConfigSQL, systemStatusSQL, scriptHistorySQL, alertHistorySQL}
+
+	// 脚本配置表，替代只能保存一个脚本的script_config
+	scriptSQL := `
+	CREATE TABLE IF NOT EXISTS script (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		path TEXT NOT NULL,
+		parameters TEXT DEFAULT '',
+		timeout INTEGER DEFAULT 0,
+		interval INTEGER DEFAULT 0,
+		mode TEXT DEFAULT '',          -- 执行模式 value/nagios
+		returns TEXT DEFAULT '',       -- 返回值对应的告警文本(JSON)
+		schedule TEXT DEFAULT '',      -- 定时执行配置(JSON)
+		enabled BOOLEAN NOT NULL DEFAULT 1,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(name)
+	);`
+
+	tables := []string{systemConfigSQL, emailConfigSQL, scriptConfigSQL, scriptReturnConfigSQL, monitorConfigSQL, systemStatusSQL, diskStatusSQL, netStatusSQL, scriptHistorySQL, alertHistorySQL, notifyChannelSQL, incidentsSQL, incidentNoteSQL, incidentProcessSQL, processWatcherSQL,
+		healthCheckSQL, checkHistorySQL, certificateSQL, logWatcherSQL, logOffsetSQL,
+		integrityWatcherSQL, integrityBaselineSQL, heartbeatSQL, heartbeatPingSQL, apiKeySQL,
+		syslogConfigSQL, syslogRuleSQL, syslogMessageSQL, mailInboundConfigSQL, scriptSQL}
 
 	for _, sql := range tables {
 		_, err := DB.Exec(sql)
@@ -150,6 +513,101 @@ func createTables() error {
 	return nil
 }
 
+// migrateTables 为旧版本数据库补充新增字段
+func migrateTables() error {
+	columns := []struct {
+		table      string
+		column     string
+		definition string
+	}{
+		{"alert_history", "channel", "TEXT DEFAULT ''"},
+		{"monitor_config", "repeat_interval", "INTEGER DEFAULT 60"},
+		{"alert_history", "incident_id", "INTEGER DEFAULT 0"},
+		{"incidents", "resolved_by", "TEXT DEFAULT ''"},
+		{"incidents", "acknowledged_by", "TEXT DEFAULT ''"},
+		{"incidents", "acknowledged_at", "INTEGER DEFAULT 0"},
+		{"incidents", "notify_count", "INTEGER DEFAULT 0"},
+		{"incidents", "last_notified_at", "INTEGER DEFAULT 0"},
+		{"monitor_config", "cpu_critical_threshold", "REAL DEFAULT 0"},
+		{"monitor_config", "mem_critical_threshold", "REAL DEFAULT 0"},
+		{"monitor_config", "disk_critical_threshold", "REAL DEFAULT 0"},
+		{"alert_history", "severity", "TEXT DEFAULT ''"},
+		{"notify_channel", "min_severity", "TEXT DEFAULT ''"},
+		{"email_config", "min_severity", "TEXT DEFAULT ''"},
+		{"monitor_config", "disk_filter", "TEXT DEFAULT ''"},
+		{"monitor_config", "disk_rules", "TEXT DEFAULT ''"},
+		{"monitor_config", "disk_min_free_gb", "REAL DEFAULT 0"},
+		{"monitor_config", "disk_critical_min_free_gb", "REAL DEFAULT 0"},
+		{"monitor_config", "inode_threshold", "REAL DEFAULT 0"},
+		{"monitor_config", "inode_critical_threshold", "REAL DEFAULT 0"},
+		{"monitor_config", "load_threshold", "REAL DEFAULT 0"},
+		{"monitor_config", "load_critical_threshold", "REAL DEFAULT 0"},
+		{"monitor_config", "swap_threshold", "REAL DEFAULT 0"},
+		{"monitor_config", "swap_critical_threshold", "REAL DEFAULT 0"},
+		{"monitor_config", "net_rx_threshold", "REAL DEFAULT 0"},
+		{"monitor_config", "net_tx_threshold", "REAL DEFAULT 0"},
+		{"monitor_config", "net_error_threshold", "REAL DEFAULT 0"},
+		{"monitor_config", "net_rx_critical_threshold", "REAL DEFAULT 0"},
+		{"monitor_config", "net_tx_critical_threshold", "REAL DEFAULT 0"},
+		{"monitor_config", "net_error_critical_threshold", "REAL DEFAULT 0"},
+		{"monitor_config", "process_top_n", "INTEGER DEFAULT 5"},
+		{"system_status", "load1", "REAL DEFAULT 0"},
+		{"system_status", "load5", "REAL DEFAULT 0"},
+		{"system_status", "load15", "REAL DEFAULT 0"},
+		{"system_status", "swap_usage", "REAL DEFAULT 0"},
+		{"health_check", "tls_config", "TEXT DEFAULT ''"},
+		{"health_check", "file_config", "TEXT DEFAULT ''"},
+		{"check_history", "value", "REAL DEFAULT 0"},
+		{"script_history", "script_id", "INTEGER DEFAULT 0"},
+		{"health_check", "schedule", "TEXT DEFAULT ''"},
+		{"script", "schedule", "TEXT DEFAULT ''"},
+		{"script", "mode", "TEXT DEFAULT ''"},
+		{"script_history", "metrics", "TEXT DEFAULT ''"},
+	}
+
+	for _, c := range columns {
+		if err := addColumnIfNotExists(c.table, c.column, c.definition); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// addColumnIfNotExists 字段不存在时为表添加字段
+func addColumnIfNotExists(table, column, definition string) error {
+	rows, err := DB.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return fmt.Errorf("查询表%s结构失败: %v", table, err)
+	}
+
+	exists := false
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, colType string
+		var defaultValue sql.NullString
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultValue, &pk); err != nil {
+			rows.Close()
+			return fmt.Errorf("扫描表%s结构失败: %v", table, err)
+		}
+		if name == column {
+			exists = true
+		}
+	}
+	rows.Close()
+
+	if exists {
+		return nil
+	}
+
+	_, err = DB.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	if err != nil {
+		return fmt.Errorf("为表%s添加字段%s失败: %v", table, column, err)
+	}
+
+	return nil
+}
+
 // initSystemName 初始化系统名称
 func initSystemName() error {
 	// 检查是否已存在系统名称
@@ -238,13 +696,13 @@ func SaveEmailConfig(config util.EmailConfig) error {
 	}
 
 	// 插入新配置
-	stmt, err := tx.Prepare("INSERT INTO email_config (smtp_host, smtp_port, username, password, from_email, to_email) VALUES (?, ?, ?, ?, ?, ?)")
+	stmt, err := tx.Prepare("INSERT INTO email_config (smtp_host, smtp_port, username, password, from_email, to_email, min_severity) VALUES (?, ?, ?, ?, ?, ?, ?)")
 	if err != nil {
 		return fmt.Errorf("准备插入语句失败: %v", err)
 	}
 	defer stmt.Close()
 
-	_, err = stmt.Exec(config.SMTPHost, config.SMTPPort, config.Username, config.Password, config.From, config.To)
+	_, err = stmt.Exec(config.SMTPHost, config.SMTPPort, config.Username, config.Password, config.From, config.To, config.MinSeverity)
 	if err != nil {
 		return fmt.Errorf("插入邮件配置失败: %v", err)
 	}
@@ -260,10 +718,10 @@ func SaveEmailConfig(config util.EmailConfig) error {
 
 // GetEmailConfig 获取邮件配置
 func GetEmailConfig() (*util.EmailConfig, error) {
-	row := DB.QueryRow("SELECT smtp_host, smtp_port, username, password, from_email, to_email FROM email_config ORDER BY id DESC LIMIT 1")
+	row := DB.QueryRow("SELECT smtp_host, smtp_port, username, password, from_email, to_email, COALESCE(min_severity, '') FROM email_config ORDER BY id DESC LIMIT 1")
 
 	var config util.EmailConfig
-	err := row.Scan(&config.SMTPHost, &config.SMTPPort, &config.Username, &config.Password, &config.From, &config.To)
+	err := row.Scan(&config.SMTPHost, &config.SMTPPort, &config.Username, &config.Password, &config.From, &config.To, &config.MinSeverity)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil // 没有配置
@@ -274,8 +732,8 @@ func GetEmailConfig() (*util.EmailConfig, error) {
 	return &config, nil
 }
 
-// SaveScriptConfig 保存脚本配置（包括定时任务）
-func SaveScriptConfig(config util.ScriptConfig) error {
+// SaveMonitorConfig 保存监控配置
+func SaveMonitorConfig(config util.MonitorConfig) error {
 	// 使用事务确保操作原子性
 	tx, err := DB.Begin()
 	if err != nil {
@@ -284,137 +742,37 @@ func SaveScriptConfig(config util.ScriptConfig) error {
 	defer tx.Rollback()
 
 	// 先删除旧配置
-	_, err = tx.Exec("DELETE FROM script_config")
-	if err != nil {
-		return fmt.Errorf("删除旧脚本配置失败: %v", err)
-	}
-
-	// 插入新配置
-	stmt, err := tx.Prepare("INSERT INTO script_config (path, parameters, timeout, interval) VALUES (?, ?, ?, ?)")
-	if err != nil {
-		return fmt.Errorf("准备插入语句失败: %v", err)
-	}
-	defer stmt.Close()
-
-	_, err = stmt.Exec(config.Path, config.Parameters, config.Timeout, config.Interval)
-	if err != nil {
-		return fmt.Errorf("插入脚本配置失败: %v", err)
-	}
-
-	// 提交事务
-	err = tx.Commit()
-	if err != nil {
-		return fmt.Errorf("提交事务失败: %v", err)
-	}
-
-	return nil
-}
-
-// GetScriptConfig 获取脚本配置
-func GetScriptConfig() (*util.ScriptConfig, error) {
-	row := DB.QueryRow("SELECT path, parameters, timeout, interval FROM script_config ORDER BY id DESC LIMIT 1")
-
-	var config util.ScriptConfig
-	err := row.Scan(&config.Path, &config.Parameters, &config.Timeout, &config.Interval)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, nil // 没有配置
-		}
-		return nil, fmt.Errorf("查询脚本配置失败: %v", err)
-	}
-
-	return &config, nil
-}
-
-// SaveScriptReturnConfig 保存脚本返回值配置
-func SaveScriptReturnConfig(returnValue int, alertText string) error {
-	// 如果告警文本为空，则删除配置
-	if alertText == "" {
-		_, err := DB.Exec("DELETE FROM script_return_config WHERE return_value = ?", returnValue)
-		if err != nil {
-			return fmt.Errorf("删除脚本返回值配置失败: %v", err)
-		}
-		return nil
-	}
-
-	// 使用事务确保操作原子性
-	tx, err := DB.Begin()
-	if err != nil {
-		return fmt.Errorf("开始事务失败: %v", err)
-	}
-	defer tx.Rollback()
-
-	// 使用UPSERT操作（INSERT OR REPLACE）
-	stmt, err := tx.Prepare("INSERT OR REPLACE INTO script_return_config (return_value, alert_text) VALUES (?, ?)")
-	if err != nil {
-		return fmt.Errorf("准备插入语句失败: %v", err)
-	}
-	defer stmt.Close()
-
-	_, err = stmt.Exec(returnValue, alertText)
-	if err != nil {
-		return fmt.Errorf("插入脚本返回值配置失败: %v", err)
-	}
-
-	// 提交事务
-	err = tx.Commit()
+	_, err = tx.Exec("DELETE FROM monitor_config")
 	if err != nil {
-		return fmt.Errorf("提交事务失败: %v", err)
+		return fmt.Errorf("删除旧监控配置失败: %v", err)
 	}
 
-	return nil
-}
-
-// GetAllScriptReturnConfigs 获取所有脚本返回值配置
-func GetAllScriptReturnConfigs() (map[int]string, error) {
-	rows, err := DB.Query("SELECT return_value, alert_text FROM script_return_config")
+	diskFilter, err := json.Marshal(config.DiskFilter)
 	if err != nil {
-		return nil, fmt.Errorf("查询脚本返回值配置失败: %v", err)
-	}
-	defer rows.Close()
-
-	configs := make(map[int]string)
-	for rows.Next() {
-		var returnValue int
-		var alertText string
-		err := rows.Scan(&returnValue, &alertText)
-		if err != nil {
-			return nil, fmt.Errorf("扫描脚本返回值配置失败: %v", err)
-		}
-		configs[returnValue] = alertText
+		return fmt.Errorf("序列化磁盘过滤规则失败: %v", err)
 	}
-
-	// 检查迭代过程中是否有错误
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("遍历结果时出错: %v", err)
-	}
-
-	return configs, nil
-}
-
-// SaveMonitorConfig 保存监控配置
-func SaveMonitorConfig(config util.MonitorConfig) error {
-	// 使用事务确保操作原子性
-	tx, err := DB.Begin()
+	diskRules, err := json.Marshal(config.DiskRules)
 	if err != nil {
-		return fmt.Errorf("开始事务失败: %v", err)
-	}
-	defer tx.Rollback()
-
-	// 先删除旧配置
-	_, err = tx.Exec("DELETE FROM monitor_config")
-	if err != nil {
-		return fmt.Errorf("删除旧监控配置失败: %v", err)
+		return fmt.Errorf("序列化挂载点阈值规则失败: %v", err)
 	}
 
 	// 插入新配置
-	stmt, err := tx.Prepare("INSERT INTO monitor_config (interval, avg_count, cpu_threshold, mem_threshold, disk_threshold) VALUES (?, ?, ?, ?, ?)")
+	stmt, err := tx.Prepare(`INSERT INTO monitor_config (interval, avg_count, cpu_threshold, mem_threshold, disk_threshold, repeat_interval,
+		cpu_critical_threshold, mem_critical_threshold, disk_critical_threshold, disk_min_free_gb, disk_critical_min_free_gb,
+		inode_threshold, inode_critical_threshold, load_threshold, load_critical_threshold, swap_threshold, swap_critical_threshold,
+		net_rx_threshold, net_tx_threshold, net_error_threshold, net_rx_critical_threshold, net_tx_critical_threshold, net_error_critical_threshold,
+		process_top_n, disk_filter, disk_rules)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
 	if err != nil {
 		return fmt.Errorf("准备插入语句失败: %v", err)
 	}
 	defer stmt.Close()
 
-	_, err = stmt.Exec(config.Interval, config.AvgCount, config.CPUThreshold, config.MemThreshold, config.DiskThreshold)
+	_, err = stmt.Exec(config.Interval, config.AvgCount, config.CPUThreshold, config.MemThreshold, config.DiskThreshold, config.RepeatInterval,
+		config.CPUCriticalThreshold, config.MemCriticalThreshold, config.DiskCriticalThreshold, config.DiskMinFreeGB, config.DiskCriticalMinFreeGB,
+		config.InodeThreshold, config.InodeCriticalThreshold, config.LoadThreshold, config.LoadCriticalThreshold, config.SwapThreshold, config.SwapCriticalThreshold,
+		config.NetRxThreshold, config.NetTxThreshold, config.NetErrorThreshold, config.NetRxCriticalThreshold, config.NetTxCriticalThreshold, config.NetErrorCriticalThreshold,
+		config.ProcessTopN, string(diskFilter), string(diskRules))
 	if err != nil {
 		return fmt.Errorf("插入监控配置失败: %v", err)
 	}
@@ -430,10 +788,20 @@ func SaveMonitorConfig(config util.MonitorConfig) error {
 
 // GetMonitorConfig 获取监控配置
 func GetMonitorConfig() (*util.MonitorConfig, error) {
-	row := DB.QueryRow("SELECT interval, avg_count, cpu_threshold, mem_threshold, disk_threshold FROM monitor_config ORDER BY id DESC LIMIT 1")
+	row := DB.QueryRow(`SELECT interval, avg_count, cpu_threshold, mem_threshold, disk_threshold, repeat_interval,
+		cpu_critical_threshold, mem_critical_threshold, disk_critical_threshold, disk_min_free_gb, disk_critical_min_free_gb,
+		inode_threshold, inode_critical_threshold, load_threshold, load_critical_threshold, swap_threshold, swap_critical_threshold,
+		net_rx_threshold, net_tx_threshold, net_error_threshold, net_rx_critical_threshold, net_tx_critical_threshold, net_error_critical_threshold,
+		process_top_n, COALESCE(disk_filter, ''), COALESCE(disk_rules, '')
+		FROM monitor_config ORDER BY id DESC LIMIT 1`)
 
 	var config util.MonitorConfig
-	err := row.Scan(&config.Interval, &config.AvgCount, &config.CPUThreshold, &config.MemThreshold, &config.DiskThreshold)
+	var diskFilter, diskRules string
+	err := row.Scan(&config.Interval, &config.AvgCount, &config.CPUThreshold, &config.MemThreshold, &config.DiskThreshold, &config.RepeatInterval,
+		&config.CPUCriticalThreshold, &config.MemCriticalThreshold, &config.DiskCriticalThreshold, &config.DiskMinFreeGB, &config.DiskCriticalMinFreeGB,
+		&config.InodeThreshold, &config.InodeCriticalThreshold, &config.LoadThreshold, &config.LoadCriticalThreshold, &config.SwapThreshold, &config.SwapCriticalThreshold,
+		&config.NetRxThreshold, &config.NetTxThreshold, &config.NetErrorThreshold, &config.NetRxCriticalThreshold, &config.NetTxCriticalThreshold, &config.NetErrorCriticalThreshold,
+		&config.ProcessTopN, &diskFilter, &diskRules)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil // 没有配置
@@ -441,6 +809,19 @@ func GetMonitorConfig() (*util.MonitorConfig, error) {
 		return nil, fmt.Errorf("查询监控配置失败: %v", err)
 	}
 
+	// 旧版本配置没有磁盘过滤规则，使用默认规则
+	config.DiskFilter = util.DefaultDiskFilter()
+	if diskFilter != "" {
+		if err = json.Unmarshal([]byte(diskFilter), &config.DiskFilter); err != nil {
+			return nil, fmt.Errorf("解析磁盘过滤规则失败: %v", err)
+		}
+	}
+	if diskRules != "" {
+		if err = json.Unmarshal([]byte(diskRules), &config.DiskRules); err != nil {
+			return nil, fmt.Errorf("解析挂载点阈值规则失败: %v", err)
+		}
+	}
+
 	return &config, nil
 }
 
@@ -449,7 +830,7 @@ func SaveSystemStatus(status util.SystemStatus) error {
 	// 带重试机制的数据库操作
 	var lastErr error
 	for i := 0; i < 3; i++ {
-		stmt, err := DB.Prepare("INSERT INTO system_status (cpu_usage, mem_usage, disk_usage, timestamp) VALUES (?, ?, ?, ?)")
+		stmt, err := DB.Prepare("INSERT INTO system_status (cpu_usage, mem_usage, disk_usage, load1, load5, load15, swap_usage, timestamp) VALUES (?, ?, ?, ?, ?, ?, ?, ?)")
 		if err != nil {
 			lastErr = fmt.Errorf("准备插入语句失败: %v", err)
 			time.Sleep(time.Millisecond * 100)
@@ -457,7 +838,7 @@ func SaveSystemStatus(status util.SystemStatus) error {
 		}
 		defer stmt.Close()
 
-		_, err = stmt.Exec(status.CPUUsage, status.MemUsage, status.DiskUsage, status.Timestamp)
+		_, err = stmt.Exec(status.CPUUsage, status.MemUsage, status.DiskUsage, status.Load1, status.Load5, status.Load15, status.SwapUsage, status.Timestamp)
 		if err != nil {
 			stmt.Close()
 			lastErr = fmt.Errorf("插入系统状态失败: %v", err)
@@ -465,18 +846,29 @@ func SaveSystemStatus(status util.SystemStatus) error {
 			continue
 		}
 
-		return nil
+		if err = SaveDiskStatus(status.Timestamp, status.DiskStats); err != nil {
+			return err
+		}
+		return SaveNetStatus(status.Timestamp, status.Networks)
 	}
 
 	return lastErr
 }
 
-// SaveScriptHistory 保存脚本执行历史
-func SaveScriptHistory(result int, output string) error {
+// SaveScriptHistory 保存脚本执行历史，metrics为Nagios插件输出的性能数据
+func SaveScriptHistory(scriptID, result int, output string, metrics []util.PerfData) error {
+	var metricsJSON []byte
+	if len(metrics) > 0 {
+		var err error
+		if metricsJSON, err = json.Marshal(metrics); err != nil {
+			return fmt.Errorf("序列化性能数据失败: %v", err)
+		}
+	}
+
 	// 带重试机制的数据库操作
 	var lastErr error
 	for i := 0; i < 3; i++ {
-		stmt, err := DB.Prepare("INSERT INTO script_history (result, output) VALUES (?, ?)")
+		stmt, err := DB.Prepare("INSERT INTO script_history (script_id, result, output, metrics) VALUES (?, ?, ?, ?)")
 		if err != nil {
 			lastErr = fmt.Errorf("准备插入语句失败: %v", err)
 			time.Sleep(time.Millisecond * 100)
@@ -484,7 +876,7 @@ func SaveScriptHistory(result int, output string) error {
 		}
 		defer stmt.Close()
 
-		_, err = stmt.Exec(result, output)
+		_, err = stmt.Exec(scriptID, result, output, string(metricsJSON))
 		if err != nil {
 			stmt.Close()
 			lastErr = fmt.Errorf("插入脚本执行历史失败: %v", err)
@@ -500,10 +892,10 @@ func SaveScriptHistory(result int, output string) error {
 
 // GetLatestSystemStatus 获取最新的系统状态
 func GetLatestSystemStatus() (*util.SystemStatus, error) {
-	row := DB.QueryRow("SELECT cpu_usage, mem_usage, disk_usage, timestamp FROM system_status ORDER BY timestamp DESC LIMIT 1")
+	row := DB.QueryRow("SELECT cpu_usage, mem_usage, disk_usage, load1, load5, load15, swap_usage, timestamp FROM system_status ORDER BY timestamp DESC LIMIT 1")
 
 	var status util.SystemStatus
-	err := row.Scan(&status.CPUUsage, &status.MemUsage, &status.DiskUsage, &status.Timestamp)
+	err := row.Scan(&status.CPUUsage, &status.MemUsage, &status.DiskUsage, &status.Load1, &status.Load5, &status.Load15, &status.SwapUsage, &status.Timestamp)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil // 没有状态记录
@@ -516,7 +908,7 @@ func GetLatestSystemStatus() (*util.SystemStatus, error) {
 
 // GetSystemStatusHistory 获取系统状态历史记录
 func GetSystemStatusHistory(limit int) ([]util.SystemStatus, error) {
-	rows, err := DB.Query("SELECT cpu_usage, mem_usage, disk_usage, timestamp FROM system_status ORDER BY timestamp DESC LIMIT ?", limit)
+	rows, err := DB.Query("SELECT cpu_usage, mem_usage, disk_usage, load1, load5, load15, swap_usage, timestamp FROM system_status ORDER BY timestamp DESC LIMIT ?", limit)
 	if err != nil {
 		return nil, fmt.Errorf("查询系统状态历史失败: %v", err)
 	}
@@ -525,7 +917,7 @@ func GetSystemStatusHistory(limit int) ([]util.SystemStatus, error) {
 	var statuses []util.SystemStatus
 	for rows.Next() {
 		var status util.SystemStatus
-		err := rows.Scan(&status.CPUUsage, &status.MemUsage, &status.DiskUsage, &status.Timestamp)
+		err := rows.Scan(&status.CPUUsage, &status.MemUsage, &status.DiskUsage, &status.Load1, &status.Load5, &status.Load15, &status.SwapUsage, &status.Timestamp)
 		if err != nil {
 			return nil, fmt.Errorf("扫描系统状态失败: %v", err)
 		}
@@ -537,20 +929,50 @@ func GetSystemStatusHistory(limit int) ([]util.SystemStatus, error) {
 		return nil, fmt.Errorf("遍历结果时出错: %v", err)
 	}
 
+	if len(statuses) == 0 {
+		return statuses, nil
+	}
+
+	// 补充各磁盘分区和网卡的状态
+	disks, err := GetDiskStatusSince(statuses[len(statuses)-1].Timestamp)
+	if err != nil {
+		return nil, err
+	}
+	networks, err := GetNetStatusSince(statuses[len(statuses)-1].Timestamp)
+	if err != nil {
+		return nil, err
+	}
+	for i := range statuses {
+		statuses[i].Networks = networks[statuses[i].Timestamp]
+		statuses[i].DiskStats = disks[statuses[i].Timestamp]
+		statuses[i].DiskUsages = make(map[string]float64, len(statuses[i].DiskStats))
+		for mountPoint, stat := range statuses[i].DiskStats {
+			statuses[i].DiskUsages[mountPoint] = stat.UsedPercent
+		}
+	}
+
 	return statuses, nil
 }
 
 // ScriptHistory 脚本执行历史结构
 type ScriptHistory struct {
-	ID        int    `json:"id"`
-	Result    int    `json:"result"`
-	Output    string `json:"output"`
-	CreatedAt string `json:"created_at"`
+	ID        int             `json:"id"`
+	ScriptID  int             `json:"script_id"`
+	Result    int             `json:"result"`
+	Output    string          `json:"output"`
+	Metrics   []util.PerfData `json:"metrics"` // Nagios插件输出的性能数据
+	CreatedAt string          `json:"created_at"`
 }
 
-// GetScriptHistory 获取脚本执行历史记录
-func GetScriptHistory(limit int) ([]ScriptHistory, error) {
-	rows, err := DB.Query("SELECT id, result, output, created_at FROM script_history ORDER BY id DESC LIMIT ?", limit)
+// GetScriptHistory 获取脚本执行历史记录，scriptID为0时返回所有脚本的记录
+func GetScriptHistory(scriptID, limit int) ([]ScriptHistory, error) {
+	query := "SELECT id, script_id, result, output, COALESCE(metrics, ''), created_at FROM script_history"
+	args := []interface{}{}
+	if scriptID != 0 {
+		query += " WHERE script_id = ?"
+		args = append(args, scriptID)
+	}
+	rows, err := DB.Query(query+" ORDER BY id DESC LIMIT ?", append(args, limit)...)
 	if err != nil {
 		return nil, fmt.Errorf("查询脚本执行历史失败: %v", err)
 	}
@@ -559,10 +981,16 @@ func GetScriptHistory(limit int) ([]ScriptHistory, error) {
 	var histories []ScriptHistory
 	for rows.Next() {
 		var history ScriptHistory
-		err := rows.Scan(&history.ID, &history.Result, &history.Output, &history.CreatedAt)
+		var metrics string
+		err := rows.Scan(&history.ID, &history.ScriptID, &history.Result, &history.Output, &metrics, &history.CreatedAt)
 		if err != nil {
 			return nil, fmt.Errorf("扫描脚本执行历史失败: %v", err)
 		}
+		if metrics != "" {
+			if err = json.Unmarshal([]byte(metrics), &history.Metrics); err != nil {
+				return nil, fmt.Errorf("解析性能数据失败: %v", err)
+			}
+		}
 		histories = append(histories, history)
 	}
 
@@ -582,15 +1010,18 @@ type AlertHistory struct {
 	Content      string `json:"content"`
 	SendStatus   bool   `json:"send_status"`
 	ErrorMessage string `json:"error_message"`
+	Channel      string `json:"channel"`
+	IncidentID   int    `json:"incident_id"`
+	Severity     string `json:"severity"`
 	CreatedAt    string `json:"created_at"`
 }
 
 // SaveAlertHistory 保存告警消息发送记录
-func SaveAlertHistory(receiver, subject, content string, sendStatus bool, errorMessage string) error {
+func SaveAlertHistory(history AlertHistory) error {
 	// 带重试机制的数据库操作
 	var lastErr error
 	for i := 0; i < 3; i++ {
-		stmt, err := DB.Prepare("INSERT INTO alert_history (receiver, subject, content, send_status, error_message) VALUES (?, ?, ?, ?, ?)")
+		stmt, err := DB.Prepare("INSERT INTO alert_history (receiver, subject, content, send_status, error_message, channel, incident_id, severity) VALUES (?, ?, ?, ?, ?, ?, ?, ?)")
 		if err != nil {
 			lastErr = fmt.Errorf("准备插入语句失败: %v", err)
 			time.Sleep(time.Millisecond * 100)
@@ -598,7 +1029,7 @@ func SaveAlertHistory(receiver, subject, content string, sendStatus bool, errorM
 		}
 		defer stmt.Close()
 
-		_, err = stmt.Exec(receiver, subject, content, sendStatus, errorMessage)
+		_, err = stmt.Exec(history.Receiver, history.Subject, history.Content, history.SendStatus, history.ErrorMessage, history.Channel, history.IncidentID, history.Severity)
 		if err != nil {
 			stmt.Close()
 			lastErr = fmt.Errorf("插入告警消息发送历史失败: %v", err)
@@ -614,7 +1045,7 @@ func SaveAlertHistory(receiver, subject, content string, sendStatus bool, errorM
 
 // GetAlertHistory 获取告警消息发送历史记录（按时间倒序）
 func GetAlertHistory(limit int) ([]AlertHistory, error) {
-	rows, err := DB.Query("SELECT id, receiver, subject, content, send_status, error_message, created_at FROM alert_history ORDER BY created_at DESC LIMIT ?", limit)
+	rows, err := DB.Query("SELECT id, receiver, subject, content, send_status, COALESCE(error_message, ''), COALESCE(channel, ''), COALESCE(incident_id, 0), COALESCE(severity, ''), created_at FROM alert_history ORDER BY created_at DESC LIMIT ?", limit)
 	if err != nil {
 		return nil, fmt.Errorf("查询告警消息发送历史失败: %v", err)
 	}
@@ -623,7 +1054,7 @@ func GetAlertHistory(limit int) ([]AlertHistory, error) {
 	var histories []AlertHistory
 	for rows.Next() {
 		var history AlertHistory
-		err := rows.Scan(&history.ID, &history.Receiver, &history.Subject, &history.Content, &history.SendStatus, &history.ErrorMessage, &history.CreatedAt)
+		err := rows.Scan(&history.ID, &history.Receiver, &history.Subject, &history.Content, &history.SendStatus, &history.ErrorMessage, &history.Channel, &history.IncidentID, &history.Severity, &history.CreatedAt)
 		if err != nil {
 			return nil, fmt.Errorf("扫描告警消息发送历史失败: %v", err)
 		}
@@ -640,7 +1071,7 @@ func GetAlertHistory(limit int) ([]AlertHistory, error) {
 
 // GetAllAlertHistory 获取所有告警消息发送历史记录（按时间倒序）
 func GetAllAlertHistory() ([]AlertHistory, error) {
-	rows, err := DB.Query("SELECT id, receiver, subject, content, send_status, error_message, created_at FROM alert_history ORDER BY created_at DESC")
+	rows, err := DB.Query("SELECT id, receiver, subject, content, send_status, COALESCE(error_message, ''), COALESCE(channel, ''), COALESCE(incident_id, 0), COALESCE(severity, ''), created_at FROM alert_history ORDER BY created_at DESC")
 	if err != nil {
 		return nil, fmt.Errorf("查询告警消息发送历史失败: %v", err)
 	}
@@ -649,7 +1080,7 @@ func GetAllAlertHistory() ([]AlertHistory, error) {
 	var histories []AlertHistory
 	for rows.Next() {
 		var history AlertHistory
-		err := rows.Scan(&history.ID, &history.Receiver, &history.Subject, &history.Content, &history.SendStatus, &history.ErrorMessage, &history.CreatedAt)
+		err := rows.Scan(&history.ID, &history.Receiver, &history.Subject, &history.Content, &history.SendStatus, &history.ErrorMessage, &history.Channel, &history.IncidentID, &history.Severity, &history.CreatedAt)
 		if err != nil {
 			return nil, fmt.Errorf("扫描告警消息发送历史失败: %v", err)
 		}
@@ -0,0 +1,201 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"warnnotice/util"
+)
+
+// SaveSyslogConfig 保存syslog接收配置
+func SaveSyslogConfig(config util.SyslogConfig) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("开始事务失败: %v", err)
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec("DELETE FROM syslog_config"); err != nil {
+		return fmt.Errorf("删除旧syslog配置失败: %v", err)
+	}
+	_, err = tx.Exec("INSERT INTO syslog_config (enabled, protocol, address, max_messages) VALUES (?, ?, ?, ?)",
+		config.Enabled, config.Protocol, config.Address, config.MaxMessages)
+	if err != nil {
+		return fmt.Errorf("插入syslog配置失败: %v", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("提交事务失败: %v", err)
+	}
+	return nil
+}
+
+// GetSyslogConfig 获取syslog接收配置
+func GetSyslogConfig() (*util.SyslogConfig, error) {
+	row := DB.QueryRow("SELECT enabled, protocol, address, max_messages FROM syslog_config ORDER BY id DESC LIMIT 1")
+
+	var config util.SyslogConfig
+	err := row.Scan(&config.Enabled, &config.Protocol, &config.Address, &config.MaxMessages)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // 没有配置
+		}
+		return nil, fmt.Errorf("查询syslog配置失败: %v", err)
+	}
+
+	return &config, nil
+}
+
+const syslogRuleColumns = "id, name, facilities, min_severity, hostname, pattern, threshold, window, severity, enabled, created_at"
+
+func scanSyslogRule(s scanner) (*util.SyslogRule, error) {
+	var r util.SyslogRule
+	var facilities string
+	err := s.Scan(&r.ID, &r.Name, &facilities, &r.MinSeverity, &r.Hostname, &r.Pattern, &r.Threshold, &r.Window, &r.Severity, &r.Enabled, &r.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if facilities != "" {
+		if err = json.Unmarshal([]byte(facilities), &r.Facilities); err != nil {
+			return nil, fmt.Errorf("解析设施列表失败: %v", err)
+		}
+	}
+	return &r, nil
+}
+
+// SaveSyslogRule 保存syslog匹配规则，ID为0时新增，否则更新
+func SaveSyslogRule(r util.SyslogRule) (int, error) {
+	facilities, err := json.Marshal(r.Facilities)
+	if err != nil {
+		return 0, fmt.Errorf("序列化设施列表失败: %v", err)
+	}
+
+	if r.ID == 0 {
+		result, err := DB.Exec(`INSERT INTO syslog_rule (name, facilities, min_severity, hostname, pattern, threshold, window, severity, enabled)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			r.Name, string(facilities), r.MinSeverity, r.Hostname, r.Pattern, r.Threshold, r.Window, r.Severity, r.Enabled)
+		if err != nil {
+			return 0, fmt.Errorf("插入syslog规则失败: %v", err)
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			return 0, fmt.Errorf("获取syslog规则ID失败: %v", err)
+		}
+		return int(id), nil
+	}
+
+	result, err := DB.Exec(`UPDATE syslog_rule SET name = ?, facilities = ?, min_severity = ?, hostname = ?, pattern = ?,
+		threshold = ?, window = ?, severity = ?, enabled = ? WHERE id = ?`,
+		r.Name, string(facilities), r.MinSeverity, r.Hostname, r.Pattern, r.Threshold, r.Window, r.Severity, r.Enabled, r.ID)
+	if err != nil {
+		return 0, fmt.Errorf("更新syslog规则失败: %v", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return 0, fmt.Errorf("syslog规则不存在: %d", r.ID)
+	}
+
+	return r.ID, nil
+}
+
+// DeleteSyslogRule 删除syslog匹配规则
+func DeleteSyslogRule(id int) error {
+	_, err := DB.Exec("DELETE FROM syslog_rule WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("删除syslog规则失败: %v", err)
+	}
+	return nil
+}
+
+// GetSyslogRules 获取syslog匹配规则，enabledOnly为true时只返回已启用的规则
+func GetSyslogRules(enabledOnly bool) ([]util.SyslogRule, error) {
+	query := "SELECT " + syslogRuleColumns + " FROM syslog_rule"
+	if enabledOnly {
+		query += " WHERE enabled = 1"
+	}
+	rows, err := DB.Query(query + " ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("查询syslog规则失败: %v", err)
+	}
+	defer rows.Close()
+
+	var rules []util.SyslogRule
+	for rows.Next() {
+		r, err := scanSyslogRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("扫描syslog规则失败: %v", err)
+		}
+		rules = append(rules, *r)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("遍历结果时出错: %v", err)
+	}
+
+	return rules, nil
+}
+
+// SaveSyslogMessages 在一个事务中批量保存收到的syslog消息
+func SaveSyslogMessages(messages []util.SyslogMessage) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("开始事务失败: %v", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`INSERT INTO syslog_message (timestamp, received_at, remote_addr, facility, severity, hostname, app_name, message, rules)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("准备插入语句失败: %v", err)
+	}
+	defer stmt.Close()
+
+	for _, msg := range messages {
+		_, err = stmt.Exec(msg.Timestamp, msg.ReceivedAt, msg.RemoteAddr, msg.Facility, msg.Severity, msg.Hostname, msg.AppName, msg.Message, msg.Rules)
+		if err != nil {
+			return fmt.Errorf("插入syslog消息失败: %v", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("提交事务失败: %v", err)
+	}
+	return nil
+}
+
+// TrimSyslogMessages 清理syslog消息，只保留最近keep条
+func TrimSyslogMessages(keep int) error {
+	_, err := DB.Exec("DELETE FROM syslog_message WHERE id <= (SELECT MAX(id) FROM syslog_message) - ?", keep)
+	if err != nil {
+		return fmt.Errorf("清理syslog消息失败: %v", err)
+	}
+	return nil
+}
+
+// GetSyslogMessages 获取最近收到的syslog消息，matchedOnly为true时只返回匹配了规则的消息
+func GetSyslogMessages(limit int, matchedOnly bool) ([]util.SyslogMessage, error) {
+	query := "SELECT id, timestamp, received_at, remote_addr, facility, severity, hostname, app_name, message, rules FROM syslog_message"
+	if matchedOnly {
+		query += " WHERE rules != ''"
+	}
+	rows, err := DB.Query(query+" ORDER BY id DESC LIMIT ?", limit)
+	if err != nil {
+		return nil, fmt.Errorf("查询syslog消息失败: %v", err)
+	}
+	defer rows.Close()
+
+	var messages []util.SyslogMessage
+	for rows.Next() {
+		var m util.SyslogMessage
+		err := rows.Scan(&m.ID, &m.Timestamp, &m.ReceivedAt, &m.RemoteAddr, &m.Facility, &m.Severity, &m.Hostname, &m.AppName, &m.Message, &m.Rules)
+		if err != nil {
+			return nil, fmt.Errorf("扫描syslog消息失败: %v", err)
+		}
+		messages = append(messages, m)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("遍历结果时出错: %v", err)
+	}
+
+	return messages, nil
+}
@@ -10,6 +10,7 @@ import (
 	"os/signal"
 	"time"
 	"warnnotice/database"
+	"warnnotice/notifier"
 	"warnnotice/pkg/e"
 	"warnnotice/pkg/settings"
 	"warnnotice/router"
@@ -22,8 +23,15 @@ import (
 func main() {
 	initDb()
 	initConfig()
+	restoreAlerts()
 	scheduler.InitMonitor()
 	scheduler.InitScriptScheduler()
+	scheduler.InitCheckScheduler()
+	scheduler.InitLogScheduler()
+	scheduler.InitIntegrityScheduler()
+	scheduler.InitHeartbeatScheduler()
+	scheduler.InitSyslogScheduler()
+	scheduler.InitMailInbound()
 	webServer()
 }
 
@@ -50,7 +58,7 @@ func webServer() {
 		}
 	}()
 
-	quit := make(chan os.Signal)
+	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt)
 	<-quit
 
@@ -65,6 +73,13 @@ func webServer() {
 	log.Println("Server exiting")
 }
 
+// 加载未恢复的告警事件，停止期间已恢复的告警条件在下一次检查时发送恢复通知
+func restoreAlerts() {
+	if err := notifier.RestoreAlertStates(); err != nil {
+		applogger.Error("加载未恢复的告警事件失败: %v", err)
+	}
+}
+
 // 初始化配置
 func initConfig() {
 	// 加载系统名称
@@ -84,20 +99,9 @@ func initConfig() {
 		e.EmailConfig = emailCfg
 	}
 
-	// 加载脚本配置
-	scriptCfg, err := database.GetScriptConfig()
-	if err != nil {
-		applogger.Error("加载脚本配置失败: %v", err)
-	} else if scriptCfg != nil {
-		e.ScriptConfig = scriptCfg
-	}
-
-	// 加载脚本返回值配置
-	scriptReturnCfg, err := database.GetAllScriptReturnConfigs()
-	if err != nil {
-		applogger.Error("加载脚本返回值配置失败: %v", err)
-	} else {
-		e.ScriptReturnConfig = scriptReturnCfg
+	// 加载通知渠道配置
+	if err := notifier.Reload(); err != nil {
+		applogger.Error("加载通知渠道配置失败: %v", err)
 	}
 
 	// 加载监控配置
@@ -107,4 +111,20 @@ func initConfig() {
 	} else if monitorCfg != nil {
 		e.MonitorConfig = monitorCfg
 	}
+
+	// 加载syslog接收配置
+	syslogCfg, err := database.GetSyslogConfig()
+	if err != nil {
+		applogger.Error("加载syslog配置失败: %v", err)
+	} else if syslogCfg != nil {
+		e.SyslogConfig = syslogCfg
+	}
+
+	// 加载邮件接收配置
+	mailInboundCfg, err := database.GetMailInboundConfig()
+	if err != nil {
+		applogger.Error("加载邮件接收配置失败: %v", err)
+	} else if mailInboundCfg != nil {
+		e.MailInboundConfig = mailInboundCfg
+	}
 }
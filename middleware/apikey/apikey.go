@@ -0,0 +1,55 @@
+package apikey
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/huobirdcenter/huobi_golang/logging/applogger"
+	"net/http"
+	"strings"
+	"time"
+	"warnnotice/database"
+	"warnnotice/pkg/e"
+	"warnnotice/util"
+)
+
+// SOURCE API密钥对应的告警来源在请求上下文中的键
+const SOURCE = "api_key_source"
+
+// Authorized 校验外部系统的API密钥，密钥通过 Authorization: Bearer 或 X-API-Key 请求头传递
+func Authorized() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		secret := c.GetHeader("X-API-Key")
+		if auth := c.GetHeader("Authorization"); secret == "" && strings.HasPrefix(auth, "Bearer ") {
+			secret = strings.TrimSpace(strings.TrimPrefix(auth, "Bearer "))
+		}
+		if secret == "" {
+			abort(c, "缺少API密钥")
+			return
+		}
+
+		key, err := database.GetAPIKeyByHash(util.HashAPIKey(secret))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"code": e.ERROR,
+				"msg":  "校验API密钥失败: " + err.Error(),
+			})
+			return
+		}
+		if key == nil {
+			abort(c, "API密钥无效")
+			return
+		}
+
+		if err = database.MarkAPIKeyUsed(key.ID, time.Now().Unix()); err != nil {
+			applogger.Error("更新API密钥使用时间失败: %v", err)
+		}
+		c.Set(SOURCE, key.Source)
+		c.Next()
+	}
+}
+
+func abort(c *gin.Context, msg string) {
+	c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+		"code": e.ERROR_AUTH,
+		"msg":  msg,
+	})
+}
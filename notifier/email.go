@@ -0,0 +1,64 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"warnnotice/database"
+	"warnnotice/util"
+)
+
+const (
+	// TypeEmail 邮件渠道
+	TypeEmail = "email"
+	// DefaultEmailName 系统默认邮件配置对应的渠道名称
+	DefaultEmailName = "email"
+)
+
+func init() {
+	Register(TypeEmail, newEmailNotifier)
+}
+
+// EmailNotifier 邮件通知渠道
+type EmailNotifier struct {
+	name   string
+	config util.EmailConfig
+}
+
+// NewEmailNotifier 创建邮件通知渠道
+func NewEmailNotifier(name string, config util.EmailConfig) *EmailNotifier {
+	return &EmailNotifier{name: name, config: config}
+}
+
+func newEmailNotifier(channel database.NotifyChannel) (Notifier, error) {
+	var config util.EmailConfig
+	if err := json.Unmarshal(channel.Config, &config); err != nil {
+		return nil, fmt.Errorf("解析邮件配置失败: %v", err)
+	}
+	if config.SMTPHost == "" || config.To == "" {
+		return nil, fmt.Errorf("SMTP服务器和收件人不能为空")
+	}
+	return NewEmailNotifier(channel.Name, config), nil
+}
+
+func (n *EmailNotifier) Name() string {
+	return n.name
+}
+
+func (n *EmailNotifier) Receiver() string {
+	return n.config.To
+}
+
+func (n *EmailNotifier) Send(ctx context.Context, alert util.Alert) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return util.SendEmail(n.config, alert.Subject(), alert.Message)
+}
+
+func (n *EmailNotifier) Test(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return util.SendTestEmail(n.config)
+}
@@ -0,0 +1,78 @@
+package notifier
+
+import (
+	"fmt"
+	"sync"
+	"time"
+	"warnnotice/database"
+	"warnnotice/util"
+)
+
+const (
+	inboundStateTTL    = 24 * time.Hour // 关闭重复通知时，超过该时间未再推送的告警清除去重状态
+	inboundMaxStates   = 10000          // 最多保留的去重状态数，超过时清除最久未推送的，只保留九成
+	inboundSweepPeriod = time.Minute    // 清除过期去重状态的间隔
+)
+
+var (
+	// 外部告警的去重状态，相同去重标识的告警按重复通知间隔发送
+	// 只在内存中保留最近推送的告警，长期未恢复的告警以告警事件为准
+	inboundTracker   = util.NewDynamicAlertTracker(repeatInterval)
+	inboundSweepMu   sync.Mutex
+	inboundLastSweep time.Time
+)
+
+// Receive 处理外部系统推送的告警，与内部告警相同进行去重、路由和记录，返回本次是否发送了通知
+func Receive(inbound util.InboundAlert) (bool, error) {
+	now := time.Now()
+	sweepInbound(repeatInterval(), now)
+	alert := inbound.Alert(now)
+	if !inboundTracker.Fire(alert.Key, alert.Severity, now) {
+		return false, nil
+	}
+	return true, Dispatch(alert)
+}
+
+// ResolveInbound 按去重标识恢复外部告警，没有未恢复的告警时返回false
+func ResolveInbound(source, dedupKey, message string) (bool, error) {
+	key := util.InboundAlertKey(source, dedupKey)
+	incident, err := database.GetOpenIncident(key)
+	if err != nil {
+		return false, err
+	}
+	state := inboundTracker.Clear(key)
+	if incident == nil && state == nil {
+		return false, nil
+	}
+
+	// 重启后去重状态丢失时使用告警事件的信息
+	title, severity, since := dedupKey, util.SeverityWarning, time.Now()
+	if state != nil {
+		severity, since = state.Severity, state.FiringSince
+	}
+	if incident != nil {
+		title, severity, since = incident.Title, incident.Severity, time.Unix(incident.StartedAt, 0)
+	}
+
+	if message == "" {
+		message = fmt.Sprintf("%s已恢复", title)
+	}
+	return true, Dispatch(util.NewResolvedAlert(source, key, title, severity, message, since))
+}
+
+// sweepInbound 清除超过重复通知间隔未再推送的去重状态，清除后再次推送时发送通知，与到达重复通知间隔时相同
+// 告警事件仍保持未恢复，恢复外部告警时以告警事件为准
+func sweepInbound(interval time.Duration, now time.Time) {
+	inboundSweepMu.Lock()
+	defer inboundSweepMu.Unlock()
+
+	if now.Sub(inboundLastSweep) >= inboundSweepPeriod {
+		inboundLastSweep = now
+		ttl := interval
+		if ttl <= 0 {
+			ttl = inboundStateTTL
+		}
+		inboundTracker.Expire(now.Add(-ttl))
+	}
+	inboundTracker.Trim(inboundMaxStates, inboundMaxStates*9/10)
+}
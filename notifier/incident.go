@@ -0,0 +1,93 @@
+package notifier
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"warnnotice/database"
+	"warnnotice/pkg/e"
+	"warnnotice/util"
+)
+
+var (
+	// 各告警来源的告警状态，启动时加载未恢复的告警事件，手动关闭告警事件时清除对应的状态
+	trackersMu sync.Mutex
+	trackers   = make(map[string]*util.AlertTracker)
+)
+
+// NewTracker 创建并登记告警来源的告警状态，重复通知间隔与系统监控配置保持一致
+func NewTracker(source string) *util.AlertTracker {
+	tracker := util.NewDynamicAlertTracker(repeatInterval)
+
+	trackersMu.Lock()
+	trackers[source] = tracker
+	trackersMu.Unlock()
+
+	return tracker
+}
+
+// RestoreAlertStates 将未恢复的告警事件加载到对应来源的告警状态中，应在启动各监控任务之前调用
+// 程序停止期间已恢复的告警条件在下一次检查时发送恢复通知并关闭告警事件
+func RestoreAlertStates() error {
+	incidents, err := database.GetOpenIncidents()
+	if err != nil {
+		return err
+	}
+
+	trackersMu.Lock()
+	defer trackersMu.Unlock()
+
+	for _, incident := range incidents {
+		tracker, exists := trackers[incident.Source]
+		if !exists {
+			continue
+		}
+		lastNotified := incident.LastNotifiedAt
+		if lastNotified == 0 {
+			lastNotified = incident.StartedAt
+		}
+		tracker.Restore(util.AlertState{
+			Key:          strings.TrimPrefix(incident.AlertKey, incident.Source+":"),
+			Severity:     incident.Severity,
+			FiringSince:  time.Unix(incident.StartedAt, 0),
+			LastNotified: time.Unix(lastNotified, 0),
+			NotifyCount:  incident.NotifyCount,
+		})
+	}
+	return nil
+}
+
+// CloseIncident 手动关闭告警事件并清除对应的告警状态，告警条件仍然存在时重新告警并开始新的告警事件
+func CloseIncident(id int, user string) error {
+	incident, err := database.GetIncident(id)
+	if err != nil {
+		return err
+	}
+	if incident == nil {
+		return fmt.Errorf("告警事件不存在")
+	}
+	if err = database.CloseIncident(id, user); err != nil {
+		return err
+	}
+
+	trackersMu.Lock()
+	tracker, exists := trackers[incident.Source]
+	trackersMu.Unlock()
+	if exists {
+		tracker.Clear(strings.TrimPrefix(incident.AlertKey, incident.Source+":"))
+	} else {
+		// 外部告警的去重状态以完整的告警标识记录
+		inboundTracker.Clear(incident.AlertKey)
+	}
+	return nil
+}
+
+// repeatInterval 重复通知间隔，与系统监控配置保持一致
+func repeatInterval() time.Duration {
+	interval := util.DefaultMonitorConfig().RepeatInterval
+	if e.MonitorConfig != nil {
+		interval = e.MonitorConfig.RepeatInterval
+	}
+	return time.Duration(interval) * time.Minute
+}
@@ -0,0 +1,208 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"github.com/huobirdcenter/huobi_golang/logging/applogger"
+	"strings"
+	"sync"
+	"time"
+	"warnnotice/database"
+	"warnnotice/pkg/e"
+	"warnnotice/util"
+)
+
+// 单个渠道发送超时时间
+const sendTimeout = 30 * time.Second
+
+// Notifier 通知渠道接口
+type Notifier interface {
+	// Name 渠道名称
+	Name() string
+	// Send 发送告警
+	Send(ctx context.Context, alert util.Alert) error
+	// Test 发送测试消息
+	Test(ctx context.Context) error
+}
+
+// Receiver 可选接口，返回用于记录发送历史的接收方
+type Receiver interface {
+	Receiver() string
+}
+
+// Builder 根据渠道配置创建通知渠道
+type Builder func(channel database.NotifyChannel) (Notifier, error)
+
+var (
+	builders = make(map[string]Builder)
+
+	mu       sync.RWMutex
+	channels []channel
+)
+
+// channel 已加载的通知渠道
+type channel struct {
+	notifier    Notifier
+	minSeverity string // 最低告警级别
+}
+
+// Register 注册通知渠道类型
+func Register(channelType string, builder Builder) {
+	builders[channelType] = builder
+}
+
+// Types 获取已注册的渠道类型
+func Types() []string {
+	types := make([]string, 0, len(builders))
+	for t := range builders {
+		types = append(types, t)
+	}
+	return types
+}
+
+// Build 根据渠道配置创建通知渠道
+func Build(channel database.NotifyChannel) (Notifier, error) {
+	builder, ok := builders[channel.Type]
+	if !ok {
+		return nil, fmt.Errorf("不支持的通知渠道类型: %s", channel.Type)
+	}
+	return builder(channel)
+}
+
+// Reload 从数据库重新加载已启用的通知渠道
+func Reload() error {
+	configs, err := database.GetNotifyChannels()
+	if err != nil {
+		return err
+	}
+
+	loaded := make([]channel, 0, len(configs))
+	for _, config := range configs {
+		if !config.Enabled {
+			continue
+		}
+		n, err := Build(config)
+		if err != nil {
+			applogger.Error("加载通知渠道[%s]失败: %v", config.Name, err)
+			continue
+		}
+		loaded = append(loaded, channel{notifier: n, minSeverity: config.MinSeverity})
+	}
+
+	mu.Lock()
+	channels = loaded
+	mu.Unlock()
+
+	return nil
+}
+
+// Channels 获取接收指定级别告警的通知渠道，包含系统默认邮件配置
+func Channels(severity string) []Notifier {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	result := make([]Notifier, 0, len(channels)+1)
+	if e.EmailConfig != nil && e.EmailConfig.SMTPHost != "" && util.SeverityAtLeast(severity, e.EmailConfig.MinSeverity) {
+		result = append(result, NewEmailNotifier(DefaultEmailName, *e.EmailConfig))
+	}
+	for _, c := range channels {
+		if util.SeverityAtLeast(severity, c.minSeverity) {
+			result = append(result, c.notifier)
+		}
+	}
+	return result
+}
+
+// Dispatch 将告警分发到所有生效的通知渠道，每个渠道记录一条发送历史
+// 带有告警条件标识的告警会归并到同一告警事件，已确认的事件在恢复前不再重复通知
+func Dispatch(alert util.Alert) error {
+	if alert.SystemName == "" {
+		alert.SystemName = e.SystemName
+	}
+	if alert.Timestamp == 0 {
+		alert.Timestamp = time.Now().Unix()
+	}
+
+	// 记录告警事件的开始和恢复
+	incidentID := 0
+	if alert.Key != "" {
+		incident, err := recordIncident(alert)
+		if err != nil {
+			applogger.Error("记录告警事件失败: %v", err)
+		}
+		if incident != nil {
+			if alert.Status != util.AlertStatusResolved && incident.Status == database.IncidentStatusAcknowledged {
+				return nil
+			}
+			incidentID = incident.ID
+		}
+	}
+
+	if incidentID > 0 && alert.Processes != nil {
+		if err := database.SaveIncidentProcess(incidentID, alert.Key, *alert.Processes); err != nil {
+			applogger.Error("保存进程快照失败: %v", err)
+		}
+	}
+
+	var errs []string
+	for _, n := range Channels(alert.Severity) {
+		err := send(n, alert, incidentID)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", n.Name(), err))
+		}
+	}
+
+	if incidentID > 0 {
+		if err := database.MarkIncidentNotified(incidentID, alert.Timestamp); err != nil {
+			applogger.Error("记录告警事件通知失败: %v", err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("告警发送失败: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// send 通过单个渠道发送告警并保存发送记录
+func send(n Notifier, alert util.Alert, incidentID int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+	defer cancel()
+
+	err := n.Send(ctx, alert)
+
+	history := database.AlertHistory{
+		Receiver:   n.Name(),
+		Subject:    alert.Subject(),
+		Content:    alert.Message,
+		SendStatus: err == nil,
+		Channel:    n.Name(),
+		IncidentID: incidentID,
+		Severity:   alert.Severity,
+	}
+	if r, ok := n.(Receiver); ok {
+		history.Receiver = r.Receiver()
+	}
+	if err != nil {
+		history.ErrorMessage = err.Error()
+	}
+
+	if saveErr := database.SaveAlertHistory(history); saveErr != nil {
+		applogger.Error("保存告警发送记录失败: %v", saveErr)
+	}
+
+	return err
+}
+
+// recordIncident 根据告警状态记录告警事件，返回告警所属的事件
+func recordIncident(alert util.Alert) (*database.Incident, error) {
+	if alert.Status != util.AlertStatusResolved {
+		return database.OpenIncident(alert)
+	}
+
+	id, err := database.ResolveIncident(alert.Key, alert.Timestamp)
+	if err != nil || id == 0 {
+		return nil, err
+	}
+	return database.GetIncident(id)
+}
@@ -0,0 +1,50 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"warnnotice/database"
+	"warnnotice/pkg/e"
+	"warnnotice/util"
+)
+
+func init() {
+	for _, robotType := range []string{util.RobotDingTalk, util.RobotWeCom, util.RobotFeishu} {
+		Register(robotType, newRobotNotifier)
+	}
+}
+
+// RobotNotifier 钉钉、企业微信、飞书群机器人通知渠道
+type RobotNotifier struct {
+	name      string
+	robotType string
+	config    util.RobotConfig
+}
+
+func newRobotNotifier(channel database.NotifyChannel) (Notifier, error) {
+	var config util.RobotConfig
+	if err := json.Unmarshal(channel.Config, &config); err != nil {
+		return nil, fmt.Errorf("解析机器人配置失败: %v", err)
+	}
+	if err := util.ValidateRobotConfig(channel.Type, config); err != nil {
+		return nil, err
+	}
+	return &RobotNotifier{name: channel.Name, robotType: channel.Type, config: config}, nil
+}
+
+func (n *RobotNotifier) Name() string {
+	return n.name
+}
+
+func (n *RobotNotifier) Receiver() string {
+	return n.robotType
+}
+
+func (n *RobotNotifier) Send(ctx context.Context, alert util.Alert) error {
+	return util.SendRobot(ctx, n.robotType, n.config, alert)
+}
+
+func (n *RobotNotifier) Test(ctx context.Context) error {
+	return util.SendTestRobot(ctx, n.robotType, n.config, e.SystemName)
+}
@@ -0,0 +1,50 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"warnnotice/database"
+	"warnnotice/pkg/e"
+	"warnnotice/util"
+)
+
+// TypeWebhook Webhook渠道
+const TypeWebhook = "webhook"
+
+func init() {
+	Register(TypeWebhook, newWebhookNotifier)
+}
+
+// WebhookNotifier Webhook通知渠道
+type WebhookNotifier struct {
+	name   string
+	config util.WebhookConfig
+}
+
+func newWebhookNotifier(channel database.NotifyChannel) (Notifier, error) {
+	var config util.WebhookConfig
+	if err := json.Unmarshal(channel.Config, &config); err != nil {
+		return nil, fmt.Errorf("解析Webhook配置失败: %v", err)
+	}
+	if err := util.ValidateWebhookConfig(config); err != nil {
+		return nil, err
+	}
+	return &WebhookNotifier{name: channel.Name, config: config}, nil
+}
+
+func (n *WebhookNotifier) Name() string {
+	return n.name
+}
+
+func (n *WebhookNotifier) Receiver() string {
+	return n.config.URL
+}
+
+func (n *WebhookNotifier) Send(ctx context.Context, alert util.Alert) error {
+	return util.SendWebhook(ctx, n.config, alert)
+}
+
+func (n *WebhookNotifier) Test(ctx context.Context) error {
+	return util.SendTestWebhook(ctx, n.config, e.SystemName)
+}
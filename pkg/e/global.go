@@ -3,12 +3,15 @@ package e
 import "warnnotice/util"
 
 var (
-	EmailConfig        *util.EmailConfig
-	MonitorConfig      *util.MonitorConfig
-	Monitor            *util.SystemMonitor
-	ScriptConfig       *util.ScriptConfig
-	ScriptReturnConfig map[int]string // 脚本返回值配置
-	SystemName         string
-	MonitorStopChan    chan bool
-	ScriptStopChan     chan bool
+	EmailConfig       *util.EmailConfig
+	MonitorConfig     *util.MonitorConfig
+	SyslogConfig      *util.SyslogConfig
+	MailInboundConfig *util.MailInboundConfig
+	Monitor           *util.SystemMonitor
+	SystemName        string
+	MonitorStopChan   chan bool
+	CheckStopChan     chan bool
+	LogStopChan       chan bool
+	IntegrityStopChan chan bool
+	SyslogStopChan    chan bool
 )
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"time"
 	"warnnotice/controller"
+	"warnnotice/middleware/apikey"
 	"warnnotice/middleware/cors"
 	"warnnotice/pkg/settings"
 )
@@ -36,7 +37,14 @@ func InitRouter() *gin.Engine {
 		// 邮件配置相关路由
 		api.POST("/email/config", controller.SetEmailConfig)
 		api.POST("/email/test", controller.TestEmail)
+		// 邮件接收配置相关路由
+		api.GET("/email/inbound/config", controller.GetMailInboundConfig)
+		api.POST("/email/inbound/config", controller.SetMailInboundConfig)
 		api.GET("/email/config", controller.GetEmailConfig)
+		// Webhook相关路由
+		api.POST("/webhook/test", controller.TestWebhook)
+		// 群机器人相关路由
+		api.POST("/robot/test", controller.TestRobot)
 
 		// 脚本配置相关路由
 		api.POST("/script/config", controller.SetScriptConfig)
@@ -47,14 +55,81 @@ func InitRouter() *gin.Engine {
 		// 脚本返回值配置相关字典给
 		api.POST("/script/return-config", controller.SetScriptReturnConfig)
 		api.GET("/script/return-configs", controller.GetScriptReturnConfigs)
+		// 多脚本配置相关路由
+		api.GET("/scripts", controller.GetScripts)
+		api.POST("/scripts", controller.SetScript)
+		api.POST("/scripts/test", controller.TestScriptConfig)
+		api.DELETE("/scripts/:id", controller.DeleteScript)
+		api.GET("/scripts/:id/history", controller.GetScriptHistoryByID)
+		api.GET("/scripts/:id/metrics", controller.GetScriptMetrics)
 		// 系统监控相关路由
 		api.POST("/monitor/config", controller.SetMonitorConfig)
 		api.GET("/monitor/config", controller.GetMonitorConfig)
 		api.GET("/monitor/status", controller.GetSystemStatus)
 		api.GET("/monitor/status/history", controller.GetSystemStatusHistory)
+		// 进程监控相关路由
+		api.GET("/process/watchers", controller.GetProcessWatchers)
+		api.POST("/process/watcher", controller.SetProcessWatcher)
+		api.POST("/process/watcher/test", controller.TestProcessWatcher)
+		api.DELETE("/process/watcher/:id", controller.DeleteProcessWatcher)
+		// 健康检查相关路由
+		api.GET("/checks", controller.GetChecks)
+		api.POST("/checks", controller.SetCheck)
+		api.POST("/checks/test", controller.TestCheck)
+		api.DELETE("/checks/:id", controller.DeleteCheck)
+		api.GET("/checks/:id/history", controller.GetCheckHistory)
+		api.GET("/checks/certificates", controller.GetCertificates)
+		// 日志监控相关路由
+		api.GET("/log/watchers", controller.GetLogWatchers)
+		api.POST("/log/watcher", controller.SetLogWatcher)
+		api.POST("/log/watcher/test", controller.TestLogWatcher)
+		api.DELETE("/log/watcher/:id", controller.DeleteLogWatcher)
+		// 文件完整性监控相关路由
+		api.GET("/integrity/watchers", controller.GetIntegrityWatchers)
+		api.POST("/integrity/watcher", controller.SetIntegrityWatcher)
+		api.DELETE("/integrity/watcher/:id", controller.DeleteIntegrityWatcher)
+		api.GET("/integrity/watcher/:id/diff", controller.GetIntegrityDiff)
+		api.POST("/integrity/watcher/:id/approve", controller.ApproveIntegrityBaseline)
+		// 心跳监控相关路由
+		api.GET("/heartbeats", controller.GetHeartbeats)
+		api.POST("/heartbeats", controller.SetHeartbeat)
+		api.DELETE("/heartbeats/:id", controller.DeleteHeartbeat)
+		api.GET("/heartbeats/:id/pings", controller.GetHeartbeatPings)
+		// 外部任务的心跳ping地址
+		api.POST("/heartbeat/:token", controller.PingHeartbeat)
+		api.POST("/heartbeat/:token/:kind", controller.PingHeartbeat)
+		// syslog接收相关路由
+		api.GET("/syslog/config", controller.GetSyslogConfig)
+		api.POST("/syslog/config", controller.SetSyslogConfig)
+		api.GET("/syslog/rules", controller.GetSyslogRules)
+		api.POST("/syslog/rule", controller.SetSyslogRule)
+		api.POST("/syslog/rule/test", controller.TestSyslogRule)
+		api.DELETE("/syslog/rule/:id", controller.DeleteSyslogRule)
+		api.GET("/syslog/messages", controller.GetSyslogMessages)
+
+		// 通知渠道相关路由
+		api.GET("/notify/channels", controller.GetNotifyChannels)
+		api.POST("/notify/channel", controller.SetNotifyChannel)
+		api.DELETE("/notify/channel/:id", controller.DeleteNotifyChannel)
+		api.POST("/notify/channel/:id/test", controller.TestNotifyChannel)
 
 		// 告警消息发送历史路由
 		api.GET("/alert/history", controller.GetAlertHistory)
+		// 外部告警API密钥路由
+		api.GET("/alert/api-keys", controller.GetAPIKeys)
+		api.POST("/alert/api-key", controller.CreateAPIKey)
+		api.DELETE("/alert/api-key/:id", controller.DeleteAPIKey)
+		// 外部系统推送告警路由，使用API密钥认证
+		alerts := api.Group("/alerts", apikey.Authorized())
+		alerts.POST("", controller.CreateAlert)
+		alerts.POST("/resolve", controller.ResolveAlert)
+		alerts.POST("/alertmanager", controller.ReceiveAlertmanager)
+		// 告警事件路由
+		api.GET("/incidents", controller.GetIncidents)
+		api.GET("/incident/:id", controller.GetIncident)
+		api.POST("/incident/:id/ack", controller.AcknowledgeIncident)
+		api.POST("/incident/:id/resolve", controller.ResolveIncident)
+		api.POST("/incident/:id/note", controller.AddIncidentNote)
 	}
 
 	r.GET("/", func(c *gin.Context) {
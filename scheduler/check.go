@@ -0,0 +1,78 @@
+package scheduler
+
+import (
+	"context"
+	"github.com/huobirdcenter/huobi_golang/logging/applogger"
+	"strconv"
+	"time"
+	"warnnotice/database"
+	"warnnotice/notifier"
+	"warnnotice/pkg/e"
+	"warnnotice/util"
+)
+
+// 健康检查的连续失败次数和告警状态，重启调度器后保留
+var checkRunner = util.NewCheckRunner(notifier.NewTracker(util.AlertSourceCheck), notifier.Dispatch)
+
+// InitCheckScheduler 为每个已启用的健康检查启动独立的定时任务
+func InitCheckScheduler() {
+	checks, err := database.GetChecks(true)
+	if err != nil {
+		applogger.Error("加载健康检查配置失败: %v", err)
+		return
+	}
+
+	// 已删除或停用的检查发送恢复通知
+	if err = checkRunner.Prune(checks); err != nil {
+		applogger.Error("发送健康检查恢复通知失败: %v", err)
+	}
+
+	// 关闭停止通道时所有检查任务退出
+	e.CheckStopChan = make(chan bool)
+	for _, c := range checks {
+		go runCheckLoop(c, e.CheckStopChan)
+	}
+}
+
+// RestartCheckScheduler 重新启动健康检查任务
+func RestartCheckScheduler() {
+	if e.CheckStopChan != nil {
+		close(e.CheckStopChan)
+	}
+
+	InitCheckScheduler()
+}
+
+// runCheckLoop 按检查间隔或cron表达式执行健康检查
+func runCheckLoop(c util.Check, stop chan bool) {
+	runScheduled("check:"+strconv.Itoa(c.ID), c.Schedule, time.Duration(c.Interval)*time.Second, stop, nil, func() {
+		runCheck(c)
+	})
+}
+
+// runCheck 执行一次健康检查，保存结果并处理告警
+func runCheck(c util.Check) {
+	result := util.RunCheck(context.Background(), c)
+
+	// 目录增长与时间窗口内最早的检查结果比较
+	if window := c.GrowthWindow(); window > 0 && result.Success {
+		earliest, err := database.GetFirstCheckResultSince(c.ID, result.Timestamp-int64(window.Seconds()))
+		if err != nil {
+			applogger.Error("获取健康检查历史失败: %v", err)
+		}
+		util.CheckGrowth(c, &result, earliest)
+	}
+
+	if err := database.SaveCheckResult(result); err != nil {
+		applogger.Error("保存健康检查结果失败: %v", err)
+	}
+	if result.Certificate != nil {
+		if err := database.SaveCertificate(c.ID, *result.Certificate); err != nil {
+			applogger.Error("保存证书信息失败: %v", err)
+		}
+	}
+
+	if err := checkRunner.Handle(c, result); err != nil {
+		applogger.Error("发送健康检查告警失败: %v", err)
+	}
+}
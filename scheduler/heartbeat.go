@@ -0,0 +1,78 @@
+package scheduler
+
+import (
+	"github.com/huobirdcenter/huobi_golang/logging/applogger"
+	"sync"
+	"time"
+	"warnnotice/database"
+	"warnnotice/notifier"
+	"warnnotice/util"
+)
+
+// heartbeatCheckInterval 检查心跳是否超时的间隔
+const heartbeatCheckInterval = 30 * time.Second
+
+var (
+	heartbeatMonitor = util.NewHeartbeatMonitor(notifier.NewTracker(util.AlertSourceHeartbeat), notifier.Dispatch)
+	// 定时检查和收到ping时的检查依次执行，避免使用过期的心跳状态
+	heartbeatMu sync.Mutex
+)
+
+// InitHeartbeatScheduler 启动心跳超时检查任务，每次检查时重新加载配置，修改配置后无需重启
+func InitHeartbeatScheduler() {
+	go func() {
+		ticker := time.NewTicker(heartbeatCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			checkHeartbeats()
+			<-ticker.C
+		}
+	}()
+}
+
+// checkHeartbeats 检查所有已启用的心跳
+func checkHeartbeats() {
+	heartbeatMu.Lock()
+	defer heartbeatMu.Unlock()
+
+	heartbeats, err := database.GetHeartbeats(true)
+	if err != nil {
+		applogger.Error("加载心跳监控配置失败: %v", err)
+		return
+	}
+
+	if err = heartbeatMonitor.Prune(heartbeats); err != nil {
+		applogger.Error("发送心跳恢复通知失败: %v", err)
+	}
+	for _, h := range heartbeats {
+		if err = checkHeartbeat(h); err != nil {
+			applogger.Error("心跳%s告警失败: %v", h.Name, err)
+		}
+	}
+}
+
+// CheckHeartbeat 收到ping后立即检查心跳，报告失败时立即告警，恢复后立即发送恢复通知
+func CheckHeartbeat(h util.Heartbeat) error {
+	heartbeatMu.Lock()
+	defer heartbeatMu.Unlock()
+
+	return checkHeartbeat(h)
+}
+
+func checkHeartbeat(h util.Heartbeat) error {
+	now := time.Now()
+
+	// 任务报告失败时在告警中附带ping内容
+	var body string
+	if h.Status(now) == util.HeartbeatStatusFailed {
+		pings, err := database.GetHeartbeatPings(h.ID, 1)
+		if err != nil {
+			applogger.Error("获取心跳ping记录失败: %v", err)
+		} else if len(pings) > 0 {
+			body = pings[0].Body
+		}
+	}
+
+	return heartbeatMonitor.Check(h, body, now)
+}
@@ -0,0 +1,107 @@
+package scheduler
+
+import (
+	"github.com/huobirdcenter/huobi_golang/logging/applogger"
+	"time"
+	"warnnotice/database"
+	"warnnotice/notifier"
+	"warnnotice/pkg/e"
+	"warnnotice/util"
+)
+
+// 文件完整性告警状态，重启调度器后保留
+var integrityMonitor = util.NewIntegrityMonitor(notifier.NewTracker(util.AlertSourceIntegrity), notifier.Dispatch)
+
+// InitIntegrityScheduler 为每个已启用的文件完整性监控启动定时扫描任务
+func InitIntegrityScheduler() {
+	watchers, err := database.GetIntegrityWatchers(true)
+	if err != nil {
+		applogger.Error("加载文件完整性监控配置失败: %v", err)
+		return
+	}
+
+	if err = integrityMonitor.Prune(watchers); err != nil {
+		applogger.Error("发送文件完整性恢复通知失败: %v", err)
+	}
+
+	e.IntegrityStopChan = make(chan bool)
+	for _, w := range watchers {
+		go runIntegrityLoop(w, e.IntegrityStopChan)
+	}
+}
+
+// RestartIntegrityScheduler 重新启动文件完整性监控任务
+func RestartIntegrityScheduler() {
+	if e.IntegrityStopChan != nil {
+		close(e.IntegrityStopChan)
+	}
+
+	InitIntegrityScheduler()
+}
+
+// runIntegrityLoop 按扫描间隔检查文件，启动时立即扫描一次
+func runIntegrityLoop(w util.IntegrityWatcher, stop chan bool) {
+	ticker := time.NewTicker(time.Duration(w.Interval) * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		if err := checkIntegrity(&w); err != nil {
+			applogger.Error("文件完整性监控%s失败: %v", w.Name, err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+	}
+}
+
+// checkIntegrity 扫描文件并与基线比较，尚未建立基线时用扫描结果建立基线
+func checkIntegrity(w *util.IntegrityWatcher) error {
+	states, err := util.ScanIntegrity(*w)
+	if err != nil {
+		return err
+	}
+
+	if w.BaselineAt == 0 {
+		return saveIntegrityBaseline(w, states)
+	}
+
+	baseline, err := database.GetIntegrityBaseline(w.ID)
+	if err != nil {
+		return err
+	}
+	return integrityMonitor.Handle(*w, util.DiffIntegrity(baseline, states), time.Now())
+}
+
+// ApproveIntegrity 用当前文件状态重新确认基线，处于告警状态时发送恢复通知
+func ApproveIntegrity(w util.IntegrityWatcher) error {
+	states, err := util.ScanIntegrity(w)
+	if err != nil {
+		return err
+	}
+	return saveIntegrityBaseline(&w, states)
+}
+
+// IntegrityDiff 扫描文件并返回与基线的差异
+func IntegrityDiff(w util.IntegrityWatcher) (util.IntegrityDiff, error) {
+	states, err := util.ScanIntegrity(w)
+	if err != nil {
+		return util.IntegrityDiff{}, err
+	}
+	baseline, err := database.GetIntegrityBaseline(w.ID)
+	if err != nil {
+		return util.IntegrityDiff{}, err
+	}
+	return util.DiffIntegrity(baseline, states), nil
+}
+
+func saveIntegrityBaseline(w *util.IntegrityWatcher, states map[string]util.FileState) error {
+	baselineAt, err := database.SaveIntegrityBaseline(w.ID, states)
+	if err != nil {
+		return err
+	}
+	w.BaselineAt = baselineAt
+	return integrityMonitor.Handle(*w, util.IntegrityDiff{}, time.Now())
+}
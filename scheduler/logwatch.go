@@ -0,0 +1,84 @@
+package scheduler
+
+import (
+	"github.com/huobirdcenter/huobi_golang/logging/applogger"
+	"time"
+	"warnnotice/database"
+	"warnnotice/notifier"
+	"warnnotice/pkg/e"
+	"warnnotice/util"
+)
+
+// logPollInterval 读取日志文件新增内容的间隔
+const logPollInterval = 5 * time.Second
+
+// 日志文件读取状态和规则匹配记录，重启调度器后保留
+var logMonitor = util.NewLogMonitor(notifier.NewTracker(util.AlertSourceLog), notifier.Dispatch)
+
+// InitLogScheduler 启动日志监控任务
+func InitLogScheduler() {
+	watchers, err := database.GetLogWatchers(true)
+	if err != nil {
+		applogger.Error("加载日志监控配置失败: %v", err)
+		return
+	}
+
+	offsets, err := database.GetLogOffsets()
+	if err != nil {
+		applogger.Error("加载日志读取位置失败: %v", err)
+	}
+	logMonitor.Restore(offsets)
+	if err = logMonitor.Prune(watchers); err != nil {
+		applogger.Error("发送日志监控恢复通知失败: %v", err)
+	}
+
+	e.LogStopChan = make(chan bool)
+	if len(watchers) == 0 {
+		return
+	}
+	go runLogLoop(watchers, offsets, e.LogStopChan)
+}
+
+// RestartLogScheduler 重新启动日志监控任务
+func RestartLogScheduler() {
+	if e.LogStopChan != nil {
+		close(e.LogStopChan)
+	}
+
+	InitLogScheduler()
+}
+
+// runLogLoop 定时读取日志文件，读取位置变化时保存到数据库
+func runLogLoop(watchers []util.LogWatcher, offsets []util.LogOffset, stop chan bool) {
+	saved := make(map[int]util.LogOffset, len(offsets))
+	for _, o := range offsets {
+		o.UpdatedAt = 0
+		saved[o.WatcherID] = o
+	}
+
+	ticker := time.NewTicker(logPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+
+		for _, w := range watchers {
+			offset, err := logMonitor.Poll(w, time.Now())
+			if err != nil {
+				applogger.Error("日志监控%s失败: %v", w.Name, err)
+			}
+			if offset == saved[w.ID] {
+				continue
+			}
+			if err = database.SaveLogOffset(offset); err != nil {
+				applogger.Error("保存日志读取位置失败: %v", err)
+				continue
+			}
+			saved[w.ID] = offset
+		}
+	}
+}
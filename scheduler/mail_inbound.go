@@ -0,0 +1,48 @@
+package scheduler
+
+import (
+	"github.com/huobirdcenter/huobi_golang/logging/applogger"
+	"warnnotice/notifier"
+	"warnnotice/pkg/e"
+	"warnnotice/util"
+)
+
+var mailServer *util.SMTPServer
+
+// InitMailInbound 启用邮件接收时启动SMTP服务
+func InitMailInbound() {
+	config := util.DefaultMailInboundConfig()
+	if e.MailInboundConfig != nil {
+		config = *e.MailInboundConfig
+	}
+	if !config.Enabled {
+		return
+	}
+
+	mailServer = util.NewSMTPServer(int64(config.MaxSizeKB)*1024, config.Accepts, func(from string, to []string, data []byte) error {
+		alert, err := util.ParseMail(data, from, to, config.Severity)
+		if err != nil {
+			applogger.Error("解析来自%s的邮件失败: %v", from, err)
+			return err
+		}
+		// 邮件已接收，通知发送失败不影响SMTP响应
+		if _, err = notifier.Receive(alert); err != nil {
+			applogger.Error("发送邮件告警失败: %v", err)
+		}
+		return nil
+	})
+	if err := mailServer.Listen(config.Address); err != nil {
+		applogger.Error("启动邮件接收失败: %v", err)
+		mailServer = nil
+	}
+}
+
+// RestartMailInbound 关闭SMTP服务后按新配置重新启动
+func RestartMailInbound() {
+	if mailServer != nil {
+		mailServer.Close()
+		mailServer = nil
+	}
+
+	InitMailInbound()
+}
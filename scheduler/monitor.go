@@ -1,50 +1,35 @@
 package scheduler
 
 import (
-	"fmt"
 	"github.com/huobirdcenter/huobi_golang/logging/applogger"
 	"time"
 	"warnnotice/database"
+	"warnnotice/notifier"
 	"warnnotice/pkg/e"
 	"warnnotice/util"
 )
 
+var (
+	// 系统监控和进程监控的告警状态，修改配置重启监控后保留
+	monitorTracker = notifier.NewTracker(util.AlertSourceMonitor)
+	processMonitor = util.NewProcessMonitor(notifier.NewTracker(util.AlertSourceProcess), notifier.Dispatch)
+	// 定时采集的网卡计数器，与实时查询系统状态分开，避免查询影响采集间隔内的速率
+	monitorNetCounter = &util.NetCounter{}
+)
+
 // 初始化监控器
 func InitMonitor() {
 	// 如果没有监控配置，使用默认配置
-	config := util.MonitorConfig{
-		Interval:      5,
-		AvgCount:      3,
-		CPUThreshold:  80.0,
-		MemThreshold:  80.0,
-		DiskThreshold: 85.0,
-	}
+	config := util.DefaultMonitorConfig()
 
 	if e.MonitorConfig != nil {
 		config = *e.MonitorConfig
 	}
 
-	e.Monitor = util.NewSystemMonitor(config, func(alertMsg string) error {
-		// 发送告警邮件
-		if e.EmailConfig != nil && e.EmailConfig.SMTPHost != "" {
-			subject := fmt.Sprintf("[%s] 系统监控告警", e.SystemName)
-			err := util.SendEmail(*e.EmailConfig, subject, alertMsg)
-			// 保存发送记录
-			sendStatus := true
-			errorMessage := ""
-			if err != nil {
-				sendStatus = false
-				errorMessage = err.Error()
-			}
-			// 保存到数据库
-			saveErr := database.SaveAlertHistory(e.EmailConfig.To, subject, alertMsg, sendStatus, errorMessage)
-			if saveErr != nil {
-				applogger.Error("保存告警发送记录失败: %v", saveErr)
-			}
-			return err
-		}
-		return nil
-	})
+	monitor := util.NewSystemMonitor(config, notifier.Dispatch)
+	// 沿用原监控器的告警状态，修改配置后不会重复发送冷却期内的告警
+	monitor.Tracker = monitorTracker
+	e.Monitor = monitor
 	// 初始化停止通道
 	e.MonitorStopChan = make(chan bool, 1)
 	// 启动定时监控任务
@@ -56,7 +41,7 @@ func InitMonitor() {
 			select {
 			case <-ticker.C:
 				// 获取系统状态
-				status, err := util.GetSystemStatus()
+				status, err := util.GetSystemStatus(config.DiskFilter, monitorNetCounter)
 				if err != nil {
 					applogger.Error("获取系统状态失败: %v", err)
 					continue
@@ -77,6 +62,9 @@ func InitMonitor() {
 					applogger.Error("检查系统阈值失败: %v", err)
 				}
 
+				// 检查进程
+				checkProcesses()
+
 			case <-e.MonitorStopChan:
 				// 收到停止信号，退出循环
 				return
@@ -98,3 +86,16 @@ func RestartMonitor() {
 	// 重新初始化监控器
 	InitMonitor()
 }
+
+// checkProcesses 按已启用的进程监控配置检查进程
+func checkProcesses() {
+	watchers, err := database.GetProcessWatchers(true)
+	if err != nil {
+		applogger.Error("获取进程监控配置失败: %v", err)
+		return
+	}
+
+	if err = processMonitor.Check(watchers); err != nil {
+		applogger.Error("检查进程失败: %v", err)
+	}
+}
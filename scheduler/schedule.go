@@ -0,0 +1,45 @@
+package scheduler
+
+import (
+	"sync"
+	"time"
+	"warnnotice/util"
+)
+
+// startedTasks 本次运行中已启动过的任务，修改配置重启任务时不再执行启动时执行
+var startedTasks sync.Map
+
+// runScheduled 按定时执行配置循环执行任务，直到stop关闭，name为任务的唯一标识
+// 设置了cron表达式时按表达式执行，否则每隔interval执行一次；onNext不为nil时在每次计算出下一次执行时间(含随机延迟)后调用
+// 设置了启动时执行的任务只在程序运行后首次启动时立即执行一次
+func runScheduled(name string, s util.ScheduleConfig, interval time.Duration, stop chan bool, onNext func(time.Time), run func()) {
+	if _, started := startedTasks.LoadOrStore(name, true); !started && s.RunOnStartup {
+		run()
+	}
+
+	// 按计划时间而不是实际执行时间计算下一次执行时间，避免随机延迟和执行耗时累积
+	planned := time.Now()
+	for {
+		planned = s.Next(interval, planned)
+		if now := time.Now(); !planned.IsZero() && planned.Before(now) {
+			planned = s.Next(interval, now)
+		}
+		if planned.IsZero() {
+			return
+		}
+
+		at := planned.Add(s.JitterDelay())
+		if onNext != nil {
+			onNext(at)
+		}
+
+		timer := time.NewTimer(time.Until(at))
+		select {
+		case <-timer.C:
+			run()
+		case <-stop:
+			timer.Stop()
+			return
+		}
+	}
+}
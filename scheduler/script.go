@@ -1,115 +1,120 @@
 package scheduler
 
 import (
-	"fmt"
 	"github.com/huobirdcenter/huobi_golang/logging/applogger"
+	"reflect"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 	"warnnotice/database"
-	"warnnotice/pkg/e"
+	"warnnotice/notifier"
 	"warnnotice/util"
 )
 
-// 初始化脚本定时执行任务
+var (
+	// 各脚本返回值的告警状态，重新加载脚本后保留
+	scriptMonitor = util.NewScriptMonitor(notifier.NewTracker(util.AlertSourceScript), notifier.Dispatch)
+	scriptMu      sync.Mutex
+	scriptTasks   = make(map[int]*scriptTask)
+)
+
+// scriptTask 单个脚本的定时任务
+type scriptTask struct {
+	script util.Script
+	stop   chan bool
+	done   chan bool                 // 任务退出后关闭
+	next   atomic.Pointer[time.Time] // 下一次执行时间
+}
+
+// InitScriptScheduler 为每个已启用且设置了执行间隔或cron表达式的脚本启动独立的定时任务
 func InitScriptScheduler() {
-	// 初始化停止通道
-	e.ScriptStopChan = make(chan bool, 1)
-	go func() {
-		var ticker *time.Ticker
-		var interval int
-
-		// 如果有脚本配置且设置了执行间隔
-		if e.ScriptConfig != nil && e.ScriptConfig.Interval > 0 {
-			interval = e.ScriptConfig.Interval
-			ticker = time.NewTicker(time.Duration(interval) * time.Minute)
-			defer ticker.Stop()
+	if err := ReloadScripts(); err != nil {
+		applogger.Error("加载脚本配置失败: %v", err)
+	}
+}
+
+// ReloadScripts 重新加载脚本配置，只重启新增、修改或删除的脚本任务，其他脚本不受影响
+func ReloadScripts() error {
+	scripts, err := database.GetScripts(true)
+	if err != nil {
+		return err
+	}
+
+	scriptMu.Lock()
+	defer scriptMu.Unlock()
+
+	// 已删除或停用的脚本发送恢复通知
+	if err = scriptMonitor.Prune(scripts); err != nil {
+		applogger.Error("发送脚本恢复通知失败: %v", err)
+	}
+
+	active := make(map[int]util.Script)
+	for _, s := range scripts {
+		if s.Scheduled() {
+			active[s.ID] = s
 		}
+	}
 
-		for {
-			// 检查配置是否发生变化
-			if e.ScriptConfig != nil && e.ScriptConfig.Interval > 0 {
-				if ticker == nil || interval != e.ScriptConfig.Interval {
-					// 配置发生变化，重新创建 ticker
-					if ticker != nil {
-						ticker.Stop()
-					}
-					interval = e.ScriptConfig.Interval
-					ticker = time.NewTicker(time.Duration(interval) * time.Minute)
-				}
-			} else {
-				// 没有配置或间隔为0，停止 ticker
-				if ticker != nil {
-					ticker.Stop()
-					ticker = nil
-				}
-			}
-
-			if ticker != nil {
-				select {
-				case <-ticker.C:
-					// 执行脚本
-					result, output, err := util.ExecuteScript(*e.ScriptConfig)
-
-					// 保存执行历史
-					err = database.SaveScriptHistory(result, output)
-					if err != nil {
-						applogger.Error("保存脚本执行历史失败: %v", err)
-					}
-
-					// 根据返回值发送不同的告警邮件
-					// 返回值为0时正常，不发送邮件
-					if result != 0 {
-						// 查找是否有对应的告警文本配置
-						if alertText, exists := e.ScriptReturnConfig[result]; exists && alertText != "" {
-							// 发送对应返回值的告警邮件
-							if e.EmailConfig != nil && e.EmailConfig.SMTPHost != "" {
-								subject := fmt.Sprintf("[%s] 脚本执行告警", e.SystemName)
-								err = util.SendEmail(*e.EmailConfig, subject, alertText)
-								// 保存发送记录
-								sendStatus := true
-								errorMessage := ""
-								if err != nil {
-									sendStatus = false
-									errorMessage = err.Error()
-								}
-								// 保存到数据库
-								saveErr := database.SaveAlertHistory(e.EmailConfig.To, subject, alertText, sendStatus, errorMessage)
-								if saveErr != nil {
-									applogger.Error("保存脚本告警发送记录失败: %v", saveErr)
-								}
-								if err != nil {
-									applogger.Error("发送脚本告警邮件失败: %v", err)
-								}
-							}
-						}
-					}
-
-				case <-e.ScriptStopChan:
-					// 收到停止信号，退出循环
-					return
-				}
-			} else {
-				// 没有配置时等待一段时间再检查
-				select {
-				case <-time.After(1 * time.Minute):
-				case <-e.ScriptStopChan:
-					// 收到停止信号，退出循环
-					return
-				}
-			}
+	stopped := make(map[int]chan bool)
+	for id, task := range scriptTasks {
+		if s, exists := active[id]; exists && reflect.DeepEqual(s, task.script) {
+			continue
+		}
+		close(task.stop)
+		stopped[id] = task.done
+		delete(scriptTasks, id)
+	}
+	for id, s := range active {
+		if _, exists := scriptTasks[id]; exists {
+			continue
 		}
-	}()
+		task := &scriptTask{script: s, stop: make(chan bool), done: make(chan bool)}
+		scriptTasks[id] = task
+		go runScriptLoop(task, stopped[id])
+	}
+	return nil
 }
 
-// 重新启动脚本调度器
-func RestartScriptScheduler() {
-	// 发送停止信号
-	if e.ScriptStopChan != nil {
-		select {
-		case e.ScriptStopChan <- true:
-		default:
-		}
+// runScriptLoop 按执行间隔或cron表达式执行脚本，previous不为nil时等待同一脚本的原任务退出后再开始，避免修改后新旧任务同时执行
+func runScriptLoop(task *scriptTask, previous chan bool) {
+	defer close(task.done)
+	if previous != nil {
+		<-previous
+	}
+
+	s := task.script
+	runScheduled("script:"+strconv.Itoa(s.ID), s.Schedule, time.Duration(s.Interval)*time.Minute, task.stop, func(next time.Time) {
+		task.next.Store(&next)
+	}, func() {
+		runScript(s)
+	})
+}
+
+// NextScriptRun 获取脚本的下一次执行时间，脚本不定时执行时返回nil
+func NextScriptRun(id int) *time.Time {
+	scriptMu.Lock()
+	defer scriptMu.Unlock()
+
+	if task, exists := scriptTasks[id]; exists {
+		return task.next.Load()
 	}
+	return nil
+}
 
-	// 重新初始化脚本调度器
-	InitScriptScheduler()
+// runScript 执行一次脚本，保存执行历史并根据返回值发送告警或恢复通知
+func runScript(s util.Script) {
+	result, output, _ := util.ExecuteScript(s.ScriptConfig)
+
+	var metrics []util.PerfData
+	if s.Mode == util.ScriptModeNagios {
+		_, metrics = util.ParseNagiosOutput(output)
+	}
+	if err := database.SaveScriptHistory(s.ID, result, output, metrics); err != nil {
+		applogger.Error("保存脚本执行历史失败: %v", err)
+	}
+
+	if err := scriptMonitor.Handle(s, result, output, time.Now()); err != nil {
+		applogger.Error("发送脚本告警失败: %v", err)
+	}
 }
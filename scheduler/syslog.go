@@ -0,0 +1,131 @@
+package scheduler
+
+import (
+	"github.com/huobirdcenter/huobi_golang/logging/applogger"
+	"sync/atomic"
+	"time"
+	"warnnotice/database"
+	"warnnotice/notifier"
+	"warnnotice/pkg/e"
+	"warnnotice/util"
+)
+
+const (
+	syslogEvalInterval = 10 * time.Second // 没有新消息时检查告警是否恢复以及清理历史消息的间隔
+	syslogQueueSize    = 1000             // 等待处理的消息数，队列满时丢弃新消息
+	syslogBatchSize    = 200              // 每次批量保存的最多消息数
+)
+
+var (
+	// 各规则的匹配记录和告警状态，重启调度器后保留
+	syslogMonitor = util.NewSyslogMonitor(notifier.NewTracker(util.AlertSourceSyslog), notifier.Dispatch)
+	syslogServer  *util.SyslogServer
+	// 队列满时丢弃的消息数，定时记录日志
+	syslogDropped atomic.Int64
+)
+
+// InitSyslogScheduler 加载syslog规则，启用时开始监听
+func InitSyslogScheduler() {
+	config := syslogConfig()
+	if err := ReloadSyslogRules(); err != nil {
+		applogger.Error("加载syslog规则失败: %v", err)
+	}
+
+	e.SyslogStopChan = make(chan bool)
+	if !config.Enabled {
+		return
+	}
+
+	queue := make(chan util.SyslogMessage, syslogQueueSize)
+	syslogServer = util.NewSyslogServer(func(msg util.SyslogMessage) {
+		select {
+		case queue <- msg:
+		default:
+			syslogDropped.Add(1)
+		}
+	})
+	if err := syslogServer.Listen(config.Protocol, config.Address); err != nil {
+		applogger.Error("启动syslog接收失败: %v", err)
+		syslogServer.Close()
+		syslogServer = nil
+		return
+	}
+	go runSyslogLoop(queue, config.MaxMessages, e.SyslogStopChan)
+}
+
+// RestartSyslogScheduler 关闭监听后按新配置重新启动
+func RestartSyslogScheduler() {
+	if e.SyslogStopChan != nil {
+		close(e.SyslogStopChan)
+	}
+	if syslogServer != nil {
+		syslogServer.Close()
+		syslogServer = nil
+	}
+
+	InitSyslogScheduler()
+}
+
+// ReloadSyslogRules 重新加载已启用的syslog规则，不影响监听，未启用syslog接收时清除所有规则
+func ReloadSyslogRules() error {
+	var rules []util.SyslogRule
+	if syslogConfig().Enabled {
+		var err error
+		if rules, err = database.GetSyslogRules(true); err != nil {
+			return err
+		}
+	}
+	return syslogMonitor.SetRules(rules)
+}
+
+func syslogConfig() util.SyslogConfig {
+	if e.SyslogConfig != nil {
+		return *e.SyslogConfig
+	}
+	return util.DefaultSyslogConfig()
+}
+
+// runSyslogLoop 依次处理收到的消息并批量保存，定时检查没有新消息的规则是否恢复并清理历史消息
+func runSyslogLoop(queue chan util.SyslogMessage, keep int, stop chan bool) {
+	ticker := time.NewTicker(syslogEvalInterval)
+	defer ticker.Stop()
+
+	batch := make([]util.SyslogMessage, 0, syslogBatchSize)
+	for {
+		select {
+		case msg := <-queue:
+			// 取出队列中已有的消息一起处理
+			batch = append(batch[:0], msg)
+		drain:
+			for len(batch) < syslogBatchSize {
+				select {
+				case msg = <-queue:
+					batch = append(batch, msg)
+				default:
+					break drain
+				}
+			}
+
+			for i := range batch {
+				if err := syslogMonitor.Handle(&batch[i], time.Now()); err != nil {
+					applogger.Error("syslog告警失败: %v", err)
+				}
+			}
+			if err := database.SaveSyslogMessages(batch); err != nil {
+				applogger.Error("保存syslog消息失败: %v", err)
+			}
+		case <-ticker.C:
+			if n := syslogDropped.Swap(0); n > 0 {
+				applogger.Error("syslog消息处理不及时，丢弃了%d条消息", n)
+			}
+			if err := syslogMonitor.Evaluate(time.Now()); err != nil {
+				applogger.Error("syslog告警失败: %v", err)
+			}
+			if err := database.TrimSyslogMessages(keep); err != nil {
+				applogger.Error("清理syslog消息失败: %v", err)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package util
+
+import (
+	"fmt"
+	"time"
+)
+
+// 告警级别
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// severityRanks 告警级别由低到高的排序
+var severityRanks = map[string]int{
+	SeverityInfo:     1,
+	SeverityWarning:  2,
+	SeverityCritical: 3,
+}
+
+// SeverityRank 获取告警级别的排序值，未知级别返回0
+func SeverityRank(severity string) int {
+	return severityRanks[severity]
+}
+
+// SeverityAtLeast 判断告警级别是否不低于指定的最低级别，最低级别为空时不限制
+func SeverityAtLeast(severity, minSeverity string) bool {
+	if minSeverity == "" {
+		return true
+	}
+	return SeverityRank(severity) >= SeverityRank(minSeverity)
+}
+
+// ValidSeverity 判断告警级别是否有效
+func ValidSeverity(severity string) bool {
+	_, ok := severityRanks[severity]
+	return ok
+}
+
+// 告警来源
+const (
+	AlertSourceMonitor = "monitor"
+	AlertSourceScript  = "script"
+)
+
+// 告警状态
+const (
+	AlertStatusFiring   = "firing"
+	AlertStatusResolved = "resolved"
+)
+
+// Alert 告警消息结构
+type Alert struct {
+	SystemName string `json:"system_name"` // 系统名称
+	Source     string `json:"source"`      // 告警来源
+	Key        string `json:"key"`         // 告警条件标识，相同条件的告警用于去重和归并
+	Title      string `json:"title"`       // 告警标题
+	Severity   string `json:"severity"`    // 告警级别
+	Status     string `json:"status"`      // 告警状态 firing/resolved
+	Message    string `json:"message"`     // 告警内容
+	Timestamp  int64  `json:"timestamp"`   // 时间戳
+	// 告警标签和注解，外部系统推送的告警携带
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// 触发告警时的进程快照，CPU和内存告警时采集
+	Processes *ProcessSnapshot `json:"processes,omitempty"`
+}
+
+// NewAlert 创建告警消息
+func NewAlert(source, title, severity, message string) Alert {
+	return Alert{
+		Source:    source,
+		Title:     title,
+		Severity:  severity,
+		Status:    AlertStatusFiring,
+		Message:   message,
+		Timestamp: time.Now().Unix(),
+	}
+}
+
+// NewResolvedAlert 创建告警恢复消息，消息中附带告警持续时间
+func NewResolvedAlert(source, key, title, severity, message string, firingSince time.Time) Alert {
+	alert := NewAlert(source, title, severity, fmt.Sprintf("%s\n持续时间: %s", message, FormatDuration(time.Since(firingSince))))
+	alert.Key = key
+	alert.Status = AlertStatusResolved
+	return alert
+}
+
+// Subject 告警主题，格式为 [系统名称] 告警标题，恢复消息标题前增加 [已恢复]
+func (a Alert) Subject() string {
+	if a.Status == AlertStatusResolved {
+		return fmt.Sprintf("[%s] [已恢复] %s", a.SystemName, a.Title)
+	}
+	return fmt.Sprintf("[%s] %s", a.SystemName, a.Title)
+}
+
+// Time 告警时间
+func (a Alert) Time() string {
+	return time.Unix(a.Timestamp, 0).Format("2006-01-02 15:04:05")
+}
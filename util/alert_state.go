@@ -0,0 +1,137 @@
+package util
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// AlertState 单个告警条件的状态
+type AlertState struct {
+	Key          string    `json:"key"`           // 告警条件
+	Severity     string    `json:"severity"`      // 本次告警期间的最高级别
+	FiringSince  time.Time `json:"firing_since"`  // 开始告警时间
+	LastNotified time.Time `json:"last_notified"` // 最近一次通知时间
+	NotifyCount  int       `json:"notify_count"`  // 通知次数
+	LastFired    time.Time `json:"last_fired"`    // 最近一次触发时间，包括未发送通知的触发
+}
+
+// AlertTracker 记录各告警条件的状态，用于告警去重和重复通知冷却
+type AlertTracker struct {
+	mu             sync.Mutex
+	repeatInterval func() time.Duration
+	states         map[string]*AlertState
+}
+
+// NewAlertTracker 创建告警状态记录器
+// repeatInterval 持续告警时重复通知的间隔，小于等于0时每次告警只通知一次
+func NewAlertTracker(repeatInterval time.Duration) *AlertTracker {
+	return NewDynamicAlertTracker(func() time.Duration { return repeatInterval })
+}
+
+// NewDynamicAlertTracker 创建告警状态记录器，每次判断是否重复通知时调用repeatInterval获取当前的重复通知间隔
+func NewDynamicAlertTracker(repeatInterval func() time.Duration) *AlertTracker {
+	return &AlertTracker{
+		repeatInterval: repeatInterval,
+		states:         make(map[string]*AlertState),
+	}
+}
+
+// Fire 标记告警条件以指定级别触发，返回本次是否需要发送通知
+// 首次触发和告警级别升高时立即通知，持续告警时按重复通知间隔通知
+func (t *AlertTracker) Fire(key, severity string, now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state, exists := t.states[key]
+	if !exists {
+		t.states[key] = &AlertState{Key: key, Severity: severity, FiringSince: now, LastNotified: now, NotifyCount: 1, LastFired: now}
+		return true
+	}
+	state.LastFired = now
+
+	escalated := SeverityRank(severity) > SeverityRank(state.Severity)
+	if escalated {
+		state.Severity = severity
+	}
+	repeatInterval := t.repeatInterval()
+	if !escalated && (repeatInterval <= 0 || now.Sub(state.LastNotified) < repeatInterval) {
+		return false
+	}
+
+	state.LastNotified = now
+	state.NotifyCount++
+	return true
+}
+
+// Clear 清除告警条件的状态，返回清除前的状态，未处于告警状态时返回nil
+func (t *AlertTracker) Clear(key string) *AlertState {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state, exists := t.states[key]
+	if !exists {
+		return nil
+	}
+	delete(t.states, key)
+	return state
+}
+
+// Restore 恢复告警条件的状态，如重启后加载未恢复的告警事件，已处于告警状态的条件保持不变
+func (t *AlertTracker) Restore(state AlertState) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, exists := t.states[state.Key]; !exists {
+		t.states[state.Key] = &state
+	}
+}
+
+// Firing 获取所有处于告警状态的条件
+func (t *AlertTracker) Firing() []AlertState {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	states := make([]AlertState, 0, len(t.states))
+	for _, state := range t.states {
+		states = append(states, *state)
+	}
+	return states
+}
+
+// Expire 清除before之前最近一次触发的告警条件，返回清除的数量
+func (t *AlertTracker) Expire(before time.Time) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	n := 0
+	for key, state := range t.states {
+		if state.LastFired.Before(before) {
+			delete(t.states, key)
+			n++
+		}
+	}
+	return n
+}
+
+// Trim 告警条件超过max个时清除最久未触发的条件，只保留keep个，返回清除的数量
+func (t *AlertTracker) Trim(max, keep int) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.states) <= max {
+		return 0
+	}
+	states := make([]*AlertState, 0, len(t.states))
+	for _, state := range t.states {
+		states = append(states, state)
+	}
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].LastFired.Before(states[j].LastFired)
+	})
+	n := len(states) - keep
+	for _, state := range states[:n] {
+		delete(t.states, state.Key)
+	}
+	return n
+}
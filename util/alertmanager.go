@@ -0,0 +1,125 @@
+package util
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AlertmanagerPayload Prometheus Alertmanager Webhook请求体(version 4)
+type AlertmanagerPayload struct {
+	Version           string              `json:"version"`
+	GroupKey          string              `json:"groupKey"`
+	TruncatedAlerts   int                 `json:"truncatedAlerts"` // 超过max_alerts被截断的告警数
+	Status            string              `json:"status"`          // 分组状态 firing/resolved
+	Receiver          string              `json:"receiver"`
+	GroupLabels       map[string]string   `json:"groupLabels"`
+	CommonLabels      map[string]string   `json:"commonLabels"`
+	CommonAnnotations map[string]string   `json:"commonAnnotations"`
+	ExternalURL       string              `json:"externalURL"`
+	Alerts            []AlertmanagerAlert `json:"alerts"`
+}
+
+// AlertmanagerAlert Alertmanager分组中的单条告警
+type AlertmanagerAlert struct {
+	Status       string            `json:"status"` // 告警状态 firing/resolved
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
+	GeneratorURL string            `json:"generatorURL"`
+	Fingerprint  string            `json:"fingerprint"` // 标签集合的指纹，用作去重标识
+}
+
+// Validate 校验Alertmanager请求体
+func (p AlertmanagerPayload) Validate() error {
+	if p.Version != "4" {
+		return fmt.Errorf("不支持的Alertmanager Webhook版本: %s", p.Version)
+	}
+	for _, a := range p.Alerts {
+		if a.Status != AlertStatusFiring && a.Status != AlertStatusResolved {
+			return fmt.Errorf("告警状态错误: %s", a.Status)
+		}
+	}
+	return nil
+}
+
+// alertmanagerSeverities Prometheus告警规则中常用的severity标签与告警级别的对应关系
+var alertmanagerSeverities = map[string]string{
+	"critical":  SeverityCritical,
+	"page":      SeverityCritical,
+	"error":     SeverityCritical,
+	"emergency": SeverityCritical,
+	"warning":   SeverityWarning,
+	"warn":      SeverityWarning,
+	"info":      SeverityInfo,
+	"notice":    SeverityInfo,
+	"none":      SeverityInfo,
+}
+
+// Inbound 转换为外部告警，summary和description注解作为告警内容，其余注解和标签附加在内容中
+func (a AlertmanagerAlert) Inbound(source string) InboundAlert {
+	title := a.Labels["alertname"]
+	if title == "" {
+		title = "Prometheus告警"
+	}
+	severity, ok := alertmanagerSeverities[strings.ToLower(a.Labels["severity"])]
+	if !ok {
+		severity = SeverityWarning
+	}
+
+	lines := make([]string, 0, 4)
+	annotations := make(map[string]string, len(a.Annotations))
+	for name, value := range a.Annotations {
+		if name != "summary" && name != "description" {
+			annotations[name] = value
+		}
+	}
+	for _, name := range []string{"summary", "description"} {
+		if a.Annotations[name] != "" {
+			lines = append(lines, a.Annotations[name])
+		}
+	}
+	if !a.StartsAt.IsZero() {
+		lines = append(lines, "开始时间: "+a.StartsAt.Local().Format("2006-01-02 15:04:05"))
+	}
+	if a.GeneratorURL != "" {
+		lines = append(lines, "查看: "+a.GeneratorURL)
+	}
+
+	return InboundAlert{
+		Source:      source,
+		Title:       title,
+		Message:     strings.Join(lines, "\n"),
+		Severity:    severity,
+		Labels:      a.Labels,
+		Annotations: annotations,
+		DedupKey:    a.dedupKey(),
+	}
+}
+
+// ResolvedMessage 告警恢复消息内容
+func (a AlertmanagerAlert) ResolvedMessage() string {
+	msg := fmt.Sprintf("%s已恢复", a.Labels["alertname"])
+	if summary := a.Annotations["summary"]; summary != "" {
+		msg += ": " + summary
+	}
+	if !a.EndsAt.IsZero() {
+		msg += "\n结束时间: " + a.EndsAt.Local().Format("2006-01-02 15:04:05")
+	}
+	return msg
+}
+
+// dedupKey 去重标识，优先使用Alertmanager计算的指纹，没有指纹时使用排序后的标签
+func (a AlertmanagerAlert) dedupKey() string {
+	if a.Fingerprint != "" {
+		return a.Fingerprint
+	}
+	pairs := make([]string, 0, len(a.Labels))
+	for name, value := range a.Labels {
+		pairs = append(pairs, name+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,183 @@
+package util
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"math"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TLSCheckConfig 证书有效期检查配置，tls和cert_file类型使用
+type TLSCheckConfig struct {
+	ServerName  string       `json:"server_name"`  // SNI主机名，为空时使用检查地址中的主机
+	ExpiryTiers []ExpiryTier `json:"expiry_tiers"` // 到期前告警档位，为空时使用默认档位
+}
+
+// ExpiryTier 证书剩余天数不超过Days时按Severity告警
+type ExpiryTier struct {
+	Days     int    `json:"days"`
+	Severity string `json:"severity"`
+}
+
+// DefaultExpiryTiers 默认到期告警档位：30天内告警，7天内严重
+func DefaultExpiryTiers() []ExpiryTier {
+	return []ExpiryTier{
+		{Days: 30, Severity: SeverityWarning},
+		{Days: 7, Severity: SeverityCritical},
+	}
+}
+
+// CertificateInfo 叶子证书信息
+type CertificateInfo struct {
+	Subject      string   `json:"subject"`
+	Issuer       string   `json:"issuer"`
+	SANs         []string `json:"sans"` // 证书包含的域名和IP
+	SerialNumber string   `json:"serial_number"`
+	NotBefore    int64    `json:"not_before"`
+	NotAfter     int64    `json:"not_after"`
+}
+
+// CertificateStatus 健康检查记录的证书及剩余有效期，用于证书汇总
+type CertificateStatus struct {
+	CheckID int    `json:"check_id"`
+	Name    string `json:"name"`
+	Target  string `json:"target"`
+	CertificateInfo
+	DaysRemaining int   `json:"days_remaining"` // 剩余有效天数，已过期时为负数
+	UpdatedAt     int64 `json:"updated_at"`
+}
+
+// NewCertificateInfo 提取证书信息
+func NewCertificateInfo(cert *x509.Certificate) CertificateInfo {
+	sans := make([]string, 0, len(cert.DNSNames)+len(cert.IPAddresses))
+	sans = append(sans, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	return CertificateInfo{
+		Subject:      cert.Subject.String(),
+		Issuer:       cert.Issuer.String(),
+		SANs:         sans,
+		SerialNumber: hex.EncodeToString(cert.SerialNumber.Bytes()),
+		NotBefore:    cert.NotBefore.Unix(),
+		NotAfter:     cert.NotAfter.Unix(),
+	}
+}
+
+// DaysRemaining 证书剩余有效天数，不足一天按0计算，已过期时为负数
+func (c CertificateInfo) DaysRemaining(now time.Time) int {
+	return int(math.Floor(time.Unix(c.NotAfter, 0).Sub(now).Hours() / 24))
+}
+
+// validateExpiryTiers 校验到期告警档位
+func validateExpiryTiers(tiers []ExpiryTier) error {
+	for _, tier := range tiers {
+		if tier.Days <= 0 {
+			return fmt.Errorf("证书到期告警天数必须大于0")
+		}
+		if !ValidSeverity(tier.Severity) {
+			return fmt.Errorf("告警级别错误: %s", tier.Severity)
+		}
+	}
+	return nil
+}
+
+// expirySeverity 根据剩余天数匹配告警档位，返回空字符串表示未到告警时间
+func expirySeverity(tiers []ExpiryTier, days int) (string, int) {
+	if len(tiers) == 0 {
+		tiers = DefaultExpiryTiers()
+	}
+	// 已过期的证书按最严重级别告警
+	if days < 0 {
+		return SeverityCritical, 0
+	}
+
+	sorted := append([]ExpiryTier(nil), tiers...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Days < sorted[j].Days })
+	for _, tier := range sorted {
+		if days <= tier.Days {
+			return tier.Severity, tier.Days
+		}
+	}
+	return "", 0
+}
+
+// runTLSCheck 连接TLS端口获取叶子证书，不校验证书链，有效期由到期告警档位判断
+func runTLSCheck(ctx context.Context, c Check) (*CertificateInfo, error) {
+	serverName := c.TLS.ServerName
+	if serverName == "" {
+		serverName, _, _ = net.SplitHostPort(c.Target)
+	}
+
+	dialer := tls.Dialer{Config: &tls.Config{ServerName: serverName, InsecureSkipVerify: true}}
+	conn, err := dialer.DialContext(ctx, "tcp", c.Target)
+	if err != nil {
+		return nil, fmt.Errorf("TLS连接失败: %v", err)
+	}
+	defer conn.Close()
+
+	certs := conn.(*tls.Conn).ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("服务端未返回证书")
+	}
+	info := NewCertificateInfo(certs[0])
+	return &info, nil
+}
+
+// runCertFileCheck 读取PEM文件中的第一个证书
+func runCertFileCheck(c Check) (*CertificateInfo, error) {
+	data, err := os.ReadFile(c.Target)
+	if err != nil {
+		return nil, fmt.Errorf("读取证书文件失败: %v", err)
+	}
+
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return nil, fmt.Errorf("证书文件%s中没有PEM格式的证书", c.Target)
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("解析证书失败: %v", err)
+		}
+		info := NewCertificateInfo(cert)
+		return &info, nil
+	}
+}
+
+// expiryCheck 检查证书剩余有效期
+func expiryCheck(c Check, cert CertificateInfo, now time.Time) thresholdCheck {
+	days := cert.DaysRemaining(now)
+	notAfter := time.Unix(cert.NotAfter, 0).Format("2006-01-02 15:04:05")
+	check := thresholdCheck{
+		Key:   checkKey(c.ID) + ":expiry",
+		Name:  fmt.Sprintf("证书%s(%s)", c.Name, c.Target),
+		Value: fmt.Sprintf("剩余%d天", days),
+	}
+
+	severity, tierDays := expirySeverity(c.TLS.ExpiryTiers, days)
+	if severity == "" {
+		return check
+	}
+	check.Severity = severity
+	detail := fmt.Sprintf("证书主题: %s\n颁发者: %s\n域名: %s\n到期时间: %s",
+		cert.Subject, cert.Issuer, strings.Join(cert.SANs, ", "), notAfter)
+	if days < 0 {
+		check.Breach = fmt.Sprintf("%s已过期\n%s", check.Name, detail)
+	} else {
+		check.Breach = fmt.Sprintf("%s剩余有效期%d天，不超过%d天\n%s", check.Name, days, tierDays, detail)
+	}
+	return check
+}
@@ -0,0 +1,357 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 健康检查类型
+const (
+	CheckTypeTCP      = "tcp"
+	CheckTypeHTTP     = "http"
+	CheckTypeTLS      = "tls"       // 连接TLS端口检查证书有效期
+	CheckTypeCertFile = "cert_file" // 读取PEM文件检查证书有效期
+	CheckTypeFileAge  = "file_age"  // 检查匹配的最新文件是否按时更新
+	CheckTypeDirSize  = "dir_size"  // 检查目录大小、文件数和增长
+)
+
+// checkTypeNames 各检查类型在告警内容中的名称
+var checkTypeNames = map[string]string{
+	CheckTypeTCP:      "TCP",
+	CheckTypeHTTP:     "HTTP",
+	CheckTypeTLS:      "TLS证书",
+	CheckTypeCertFile: "证书文件",
+	CheckTypeFileAge:  "文件更新",
+	CheckTypeDirSize:  "目录大小",
+}
+
+// AlertSourceCheck 健康检查告警来源
+const AlertSourceCheck = "check"
+
+// Check 健康检查配置
+type Check struct {
+	ID               int             `json:"id"`
+	Name             string          `json:"name"`
+	Type             string          `json:"type"`              // 检查类型 tcp/http
+	Target           string          `json:"target"`            // tcp、tls为host:port，http为URL，cert_file为证书文件路径，file_age为文件通配符，dir_size为目录
+	Timeout          int             `json:"timeout"`           // 超时时间(秒)
+	Interval         int             `json:"interval"`          // 检查间隔(秒)，设置cron表达式时不使用
+	FailureThreshold int             `json:"failure_threshold"` // 连续失败多少次后告警
+	Severity         string          `json:"severity"`          // 告警级别，默认critical
+	Enabled          bool            `json:"enabled"`
+	HTTP             HTTPCheckConfig `json:"http"`     // HTTP检查配置，仅http类型使用
+	TLS              TLSCheckConfig  `json:"tls"`      // 证书有效期检查配置
+	File             FileCheckConfig `json:"file"`     // 文件检查配置
+	Schedule         ScheduleConfig  `json:"schedule"` // 定时执行配置
+	CreatedAt        string          `json:"created_at"`
+}
+
+// HTTPCheckConfig HTTP检查配置
+type HTTPCheckConfig struct {
+	Method             string            `json:"method"`               // 请求方法，默认GET
+	Headers            map[string]string `json:"headers"`              // 请求头
+	Body               string            `json:"body"`                 // 请求体
+	ExpectedStatus     []int             `json:"expected_status"`      // 期望的状态码，为空时要求2xx
+	BodyContains       string            `json:"body_contains"`        // 响应内容需包含的字符串
+	BodyRegex          string            `json:"body_regex"`           // 响应内容需匹配的正则
+	MaxLatency         int               `json:"max_latency"`          // 最大响应时间(毫秒)，0表示不限制
+	InsecureSkipVerify bool              `json:"insecure_skip_verify"` // 跳过TLS证书校验
+}
+
+// CheckResult 健康检查结果
+type CheckResult struct {
+	ID         int     `json:"id"`
+	CheckID    int     `json:"check_id"`
+	Success    bool    `json:"success"`
+	Latency    int64   `json:"latency"`     // 响应时间(毫秒)
+	StatusCode int     `json:"status_code"` // HTTP状态码
+	Message    string  `json:"message"`     // 失败原因
+	Value      float64 `json:"value"`       // 指标值，file_age为最新文件已存在的秒数，dir_size为目录大小(字节)
+	Timestamp  int64   `json:"timestamp"`
+
+	Certificate *CertificateInfo `json:"certificate,omitempty"` // 证书信息，tls和cert_file类型检查成功时返回
+}
+
+// DefaultCheck 默认健康检查配置
+func DefaultCheck() Check {
+	return Check{
+		Type:             CheckTypeHTTP,
+		Timeout:          10,
+		Interval:         60,
+		FailureThreshold: 3,
+		Enabled:          true,
+		Schedule:         ScheduleConfig{RunOnStartup: true},
+	}
+}
+
+// Validate 校验健康检查配置
+func (c Check) Validate() error {
+	if c.Name == "" {
+		return fmt.Errorf("检查名称不能为空")
+	}
+	if c.Timeout <= 0 || (c.Interval <= 0 && c.Schedule.Cron == "") {
+		return fmt.Errorf("超时时间和检查间隔必须大于0")
+	}
+	if err := c.Schedule.Validate(); err != nil {
+		return err
+	}
+	if c.FailureThreshold <= 0 {
+		return fmt.Errorf("连续失败次数必须大于0")
+	}
+	if c.Severity != "" && !ValidSeverity(c.Severity) {
+		return fmt.Errorf("告警级别错误: %s", c.Severity)
+	}
+
+	switch c.Type {
+	case CheckTypeTCP, CheckTypeTLS:
+		if _, _, err := net.SplitHostPort(c.Target); err != nil {
+			return fmt.Errorf("检查地址%s格式错误，应为host:port: %v", c.Target, err)
+		}
+	case CheckTypeHTTP:
+		u, err := url.Parse(c.Target)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("检查地址%s不是有效的http(s) URL", c.Target)
+		}
+		if c.HTTP.BodyRegex != "" {
+			if _, err := regexp.Compile(c.HTTP.BodyRegex); err != nil {
+				return fmt.Errorf("响应内容正则格式错误: %v", err)
+			}
+		}
+	case CheckTypeCertFile:
+		if c.Target == "" {
+			return fmt.Errorf("证书文件路径不能为空")
+		}
+	case CheckTypeFileAge, CheckTypeDirSize:
+		return validateFileCheck(c)
+	default:
+		return fmt.Errorf("不支持的检查类型: %s", c.Type)
+	}
+	if c.Type == CheckTypeTLS || c.Type == CheckTypeCertFile {
+		return validateExpiryTiers(c.TLS.ExpiryTiers)
+	}
+	return nil
+}
+
+// RunCheck 执行一次健康检查
+func RunCheck(ctx context.Context, c Check) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(c.Timeout)*time.Second)
+	defer cancel()
+
+	result := CheckResult{CheckID: c.ID, Timestamp: time.Now().Unix()}
+	start := time.Now()
+	var err error
+	switch c.Type {
+	case CheckTypeTCP:
+		err = runTCPCheck(ctx, c)
+	case CheckTypeHTTP:
+		result.StatusCode, err = runHTTPCheck(ctx, c, start)
+	case CheckTypeTLS:
+		result.Certificate, err = runTLSCheck(ctx, c)
+	case CheckTypeCertFile:
+		result.Certificate, err = runCertFileCheck(c)
+	case CheckTypeFileAge:
+		result.Value, err = runFileAgeCheck(c, start)
+	case CheckTypeDirSize:
+		result.Value, err = runDirSizeCheck(ctx, c)
+	default:
+		err = fmt.Errorf("不支持的检查类型: %s", c.Type)
+	}
+	result.Latency = time.Since(start).Milliseconds()
+
+	result.Success = err == nil
+	if err != nil {
+		result.Message = err.Error()
+	}
+	return result
+}
+
+func runTCPCheck(ctx context.Context, c Check) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", c.Target)
+	if err != nil {
+		return fmt.Errorf("连接失败: %v", err)
+	}
+	return conn.Close()
+}
+
+func runHTTPCheck(ctx context.Context, c Check, start time.Time) (int, error) {
+	method := c.HTTP.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	var body io.Reader
+	if c.HTTP.Body != "" {
+		body = strings.NewReader(c.HTTP.Body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.Target, body)
+	if err != nil {
+		return 0, fmt.Errorf("创建请求失败: %v", err)
+	}
+	for k, v := range c.HTTP.Headers {
+		req.Header.Set(k, v)
+	}
+	// Host请求头需要单独设置
+	if host, ok := c.HTTP.Headers["Host"]; ok {
+		req.Host = host
+	}
+
+	client := NewHTTPClient(c.Timeout, c.HTTP.InsecureSkipVerify)
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return resp.StatusCode, fmt.Errorf("读取响应失败: %v", err)
+	}
+	latency := time.Since(start).Milliseconds()
+
+	if !expectedStatus(c.HTTP.ExpectedStatus, resp.StatusCode) {
+		return resp.StatusCode, fmt.Errorf("响应状态码%d不符合预期: %s", resp.StatusCode, truncate(string(respBody), 200))
+	}
+	if c.HTTP.BodyContains != "" && !strings.Contains(string(respBody), c.HTTP.BodyContains) {
+		return resp.StatusCode, fmt.Errorf("响应内容不包含%q", c.HTTP.BodyContains)
+	}
+	if c.HTTP.BodyRegex != "" {
+		re, err := regexp.Compile(c.HTTP.BodyRegex)
+		if err != nil {
+			return resp.StatusCode, fmt.Errorf("响应内容正则格式错误: %v", err)
+		}
+		if !re.Match(respBody) {
+			return resp.StatusCode, fmt.Errorf("响应内容不匹配正则%q", c.HTTP.BodyRegex)
+		}
+	}
+	if c.HTTP.MaxLatency > 0 && latency > int64(c.HTTP.MaxLatency) {
+		return resp.StatusCode, fmt.Errorf("响应时间%dms超过%dms", latency, c.HTTP.MaxLatency)
+	}
+	return resp.StatusCode, nil
+}
+
+// expectedStatus 判断状态码是否符合预期，未配置时要求2xx
+func expectedStatus(expected []int, code int) bool {
+	if len(expected) == 0 {
+		return code >= 200 && code < 300
+	}
+	for _, e := range expected {
+		if e == code {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckRunner 记录各健康检查的连续失败次数，达到阈值时告警，检查成功后发送恢复通知
+type CheckRunner struct {
+	mu       sync.Mutex
+	failures map[int]int
+	notifier CheckNotifier
+}
+
+// NewCheckRunner 创建健康检查告警处理器
+func NewCheckRunner(tracker *AlertTracker, alertFunc func(Alert) error) *CheckRunner {
+	return &CheckRunner{
+		failures: make(map[int]int),
+		notifier: CheckNotifier{
+			Source:    AlertSourceCheck,
+			Title:     "健康检查告警",
+			Tracker:   tracker,
+			AlertFunc: alertFunc,
+		},
+	}
+}
+
+// Handle 处理一次检查结果
+func (r *CheckRunner) Handle(c Check, result CheckResult) error {
+	r.mu.Lock()
+	if result.Success {
+		delete(r.failures, c.ID)
+	} else {
+		r.failures[c.ID]++
+	}
+	failures := r.failures[c.ID]
+	r.mu.Unlock()
+
+	now := time.Unix(result.Timestamp, 0)
+	var errs []string
+	// 证书有效期作为单独的告警条件，获取证书失败时保持当前状态
+	if result.Certificate != nil {
+		if err := r.notifier.Update(expiryCheck(c, *result.Certificate, now), now); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	check := thresholdCheck{
+		Key:   checkKey(c.ID),
+		Name:  fmt.Sprintf("%s检查%s(%s)", checkTypeNames[c.Type], c.Name, c.Target),
+		Value: checkValue(c, result),
+	}
+	if failures >= c.FailureThreshold {
+		check.Severity = c.Severity
+		if check.Severity == "" {
+			check.Severity = SeverityCritical
+		}
+		check.Breach = fmt.Sprintf("%s连续失败%d次: %s", check.Name, failures, result.Message)
+	}
+
+	// 未达到连续失败次数时保持当前告警状态
+	if result.Success || check.Breach != "" {
+		if err := r.notifier.Update(check, now); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// Prune 清除已删除或停用的检查的状态，处于告警状态的发送恢复通知
+func (r *CheckRunner) Prune(active []Check) error {
+	ids := make(map[string]bool, len(active))
+	for _, c := range active {
+		ids[checkKey(c.ID)] = true
+	}
+
+	r.mu.Lock()
+	for id := range r.failures {
+		if !ids[checkKey(id)] {
+			delete(r.failures, id)
+		}
+	}
+	r.mu.Unlock()
+
+	return r.notifier.Prune(func(key string) bool {
+		// 告警条件标识以检查ID开头
+		id, _, _ := strings.Cut(key, ":")
+		return ids[id]
+	}, "健康检查已删除或停用")
+}
+
+// checkValue 格式化检查结果中的指标值
+func checkValue(c Check, result CheckResult) string {
+	switch c.Type {
+	case CheckTypeFileAge:
+		return fmt.Sprintf("最新文件已存在%.0f分钟", result.Value/60)
+	case CheckTypeDirSize:
+		return fmt.Sprintf("目录大小%.2fMiB", result.Value/MiB)
+	default:
+		return fmt.Sprintf("响应时间%dms", result.Latency)
+	}
+}
+
+func checkKey(id int) string {
+	return strconv.Itoa(id)
+}
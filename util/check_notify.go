@@ -0,0 +1,128 @@
+package util
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// thresholdCheck 单个告警条件的检查结果
+type thresholdCheck struct {
+	Key      string // 条件标识，如 cpu、mem、disk:/data
+	Name     string // 条件名称
+	Value    string // 当前值
+	Breach   string // 超过阈值时的告警描述，为空表示正常
+	Severity string // 告警级别
+}
+
+// CheckNotifier 根据检查结果维护告警状态，持续异常时按重复通知间隔发送告警，恢复正常时发送恢复通知
+// 同一个Tracker只应由一个CheckNotifier使用，本次检查结果中不存在的告警条件视为已恢复
+type CheckNotifier struct {
+	Source    string            // 告警来源
+	Title     string            // 告警标题
+	Tracker   *AlertTracker     // 各告警条件的状态
+	AlertFunc func(Alert) error // 告警函数
+}
+
+// Notify 根据检查结果发送告警和恢复通知，decorate不为空时用于在发送前补充告警内容
+func (n CheckNotifier) Notify(checks []thresholdCheck, now time.Time, decorate func(check thresholdCheck, alert *Alert) error) error {
+	errs := make([]string, 0)
+	current := make(map[string]thresholdCheck, len(checks))
+	for _, check := range checks {
+		current[check.Key] = check
+	}
+
+	// 已恢复正常的条件清除告警状态并发送恢复通知
+	for _, state := range n.Tracker.Firing() {
+		check, exists := current[state.Key]
+		if exists && check.Breach != "" {
+			continue
+		}
+
+		resolvedMsg := fmt.Sprintf("%s已恢复正常", state.Key)
+		if exists {
+			resolvedMsg = fmt.Sprintf("%s已恢复正常，当前%s", check.Name, check.Value)
+		}
+		if err := n.Resolve(state.Key, resolvedMsg); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	sort.Slice(checks, func(i, j int) bool { return checks[i].Key < checks[j].Key })
+
+	for _, check := range checks {
+		if check.Breach == "" {
+			continue
+		}
+		if err := n.fire(check, now, decorate); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// Update 根据单个告警条件的检查结果发送告警或恢复通知，不影响其他告警条件
+func (n CheckNotifier) Update(check thresholdCheck, now time.Time) error {
+	if check.Breach != "" {
+		return n.fire(check, now, nil)
+	}
+	return n.Resolve(check.Key, fmt.Sprintf("%s已恢复正常，当前%s", check.Name, check.Value))
+}
+
+// Resolve 清除告警条件的状态并发送恢复通知，未处于告警状态时不发送
+func (n CheckNotifier) Resolve(key, message string) error {
+	state := n.Tracker.Clear(key)
+	if state == nil {
+		return nil
+	}
+	alert := NewResolvedAlert(n.Source, n.Source+":"+key, n.Title, state.Severity, message, state.FiringSince)
+	return n.AlertFunc(alert)
+}
+
+// Prune 清除keep返回false的告警条件的状态并发送恢复通知，用于告警配置被删除或停用时
+func (n CheckNotifier) Prune(keep func(key string) bool, message string) error {
+	var errs []string
+	for _, state := range n.Tracker.Firing() {
+		if keep(state.Key) {
+			continue
+		}
+		if err := n.Resolve(state.Key, message); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// fire 标记告警条件触发并发送告警，处于冷却期内时不重复发送
+func (n CheckNotifier) fire(check thresholdCheck, now time.Time, decorate func(check thresholdCheck, alert *Alert) error) error {
+	if !n.Tracker.Fire(check.Key, check.Severity, now) {
+		return nil
+	}
+
+	alertMsg := n.Title + ":\n" + fmt.Sprintf("时间: %s\n", now.Format("2006-01-02 15:04:05")) + check.Breach + "\n"
+	alert := NewAlert(n.Source, n.Title, check.Severity, alertMsg)
+	alert.Key = n.Source + ":" + check.Key
+
+	var errs []string
+	if decorate != nil {
+		if err := decorate(check, &alert); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if err := n.AlertFunc(alert); err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
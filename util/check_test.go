@@ -0,0 +1,140 @@
+package util
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newCheckTestServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"up","version":"1.2.3"}`))
+	})
+	mux.HandleFunc("/error", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	})
+	mux.HandleFunc("/created", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("X-Token") != "secret" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		w.Write([]byte("ok"))
+	})
+	mux.HandleFunc("/redirect", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/ok", http.StatusFound)
+	})
+	mux.HandleFunc("/loop", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/loop", http.StatusFound)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRunHTTPCheck(t *testing.T) {
+	server := newCheckTestServer(t)
+
+	tests := []struct {
+		name        string
+		path        string
+		http        HTTPCheckConfig
+		wantSuccess bool
+		wantStatus  int
+		wantMessage string
+	}{
+		{name: "默认要求2xx", path: "/ok", wantSuccess: true, wantStatus: 200},
+		{name: "非2xx失败", path: "/error", wantStatus: 500, wantMessage: "状态码500"},
+		{name: "期望的状态码", path: "/error", http: HTTPCheckConfig{ExpectedStatus: []int{500}}, wantSuccess: true, wantStatus: 500},
+		{name: "状态码不在期望列表", path: "/ok", http: HTTPCheckConfig{ExpectedStatus: []int{201, 204}}, wantStatus: 200, wantMessage: "不符合预期"},
+		{
+			name:        "请求方法和请求头",
+			path:        "/created",
+			http:        HTTPCheckConfig{Method: http.MethodPost, Headers: map[string]string{"X-Token": "secret"}, ExpectedStatus: []int{201}},
+			wantSuccess: true,
+			wantStatus:  201,
+		},
+		{name: "包含字符串", path: "/ok", http: HTTPCheckConfig{BodyContains: `"status":"up"`}, wantSuccess: true, wantStatus: 200},
+		{name: "不包含字符串", path: "/ok", http: HTTPCheckConfig{BodyContains: "down"}, wantStatus: 200, wantMessage: "不包含"},
+		{name: "匹配正则", path: "/ok", http: HTTPCheckConfig{BodyRegex: `"version":"1\.\d+\.\d+"`}, wantSuccess: true, wantStatus: 200},
+		{name: "不匹配正则", path: "/ok", http: HTTPCheckConfig{BodyRegex: `"version":"2\.`}, wantStatus: 200, wantMessage: "不匹配正则"},
+		{name: "响应时间未超过", path: "/slow", http: HTTPCheckConfig{MaxLatency: 5000}, wantSuccess: true, wantStatus: 200},
+		{name: "响应时间超过", path: "/slow", http: HTTPCheckConfig{MaxLatency: 10}, wantStatus: 200, wantMessage: "响应时间"},
+		{name: "跟随重定向", path: "/redirect", http: HTTPCheckConfig{BodyContains: "up"}, wantSuccess: true, wantStatus: 200},
+		{name: "重定向循环", path: "/loop", wantMessage: "请求失败"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := DefaultCheck()
+			c.Name = tt.name
+			c.Type = CheckTypeHTTP
+			c.Target = server.URL + tt.path
+			c.HTTP = tt.http
+			if err := c.Validate(); err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+
+			result := RunCheck(context.Background(), c)
+			if result.Success != tt.wantSuccess {
+				t.Fatalf("Success = %v, want %v, message: %s", result.Success, tt.wantSuccess, result.Message)
+			}
+			if result.StatusCode != tt.wantStatus {
+				t.Errorf("StatusCode = %d, want %d", result.StatusCode, tt.wantStatus)
+			}
+			if !strings.Contains(result.Message, tt.wantMessage) {
+				t.Errorf("Message = %q, want containing %q", result.Message, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestRunTCPCheck(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	c := DefaultCheck()
+	c.Name = "tcp"
+	c.Type = CheckTypeTCP
+	c.Target = l.Addr().String()
+	if result := RunCheck(context.Background(), c); !result.Success {
+		t.Fatalf("连接监听中的端口失败: %s", result.Message)
+	}
+
+	// 关闭监听后端口拒绝连接
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Target = closed.Addr().String()
+	closed.Close()
+
+	result := RunCheck(context.Background(), c)
+	if result.Success {
+		t.Fatal("连接已关闭的端口应失败")
+	}
+	if !strings.Contains(result.Message, "连接失败") {
+		t.Errorf("Message = %q, want containing %q", result.Message, "连接失败")
+	}
+}
@@ -0,0 +1,235 @@
+package util
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronField cron表达式单个字段的取值范围
+type cronField struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	cronSecond = cronField{name: "秒", min: 0, max: 59}
+	cronMinute = cronField{name: "分", min: 0, max: 59}
+	cronHour   = cronField{name: "时", min: 0, max: 23}
+	cronDom    = cronField{name: "日", min: 1, max: 31}
+	cronMonth  = cronField{name: "月", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// 星期允许0和7表示周日
+	cronDow = cronField{name: "星期", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// cronDescriptors 预定义的cron表达式
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// cronMaxYears 计算下一次执行时间时最多查找的年数，如2月30日这样永远不会执行的表达式返回零值
+const cronMaxYears = 5
+
+// CronSchedule 解析后的cron表达式
+type CronSchedule struct {
+	second, minute, hour, dom, month, dow uint64
+	// 日和星期都不以*开头时满足其一即可，否则都要满足
+	domAny, dowAny bool
+	// 小时以*开头时按实际经过的时间执行，否则按当地时间每天只执行一次
+	hourAny  bool
+	location *time.Location
+}
+
+// ParseCron 解析5个字段(分 时 日 月 星期)或6个字段(秒 分 时 日 月 星期)的cron表达式，
+// 支持 * ? , - / 、月份和星期的英文缩写以及@daily等预定义表达式，loc为nil时使用本地时区
+func ParseCron(expr string, loc *time.Location) (*CronSchedule, error) {
+	if loc == nil {
+		loc = time.Local
+	}
+	expr = strings.TrimSpace(expr)
+	if descriptor, ok := cronDescriptors[strings.ToLower(expr)]; ok {
+		expr = descriptor
+	}
+
+	fields := strings.Fields(expr)
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return nil, fmt.Errorf("cron表达式%q应为5个或6个字段", expr)
+	}
+
+	s := &CronSchedule{location: loc}
+	var err error
+	if s.second, err = cronSecond.parse(fields[0]); err != nil {
+		return nil, err
+	}
+	if s.minute, err = cronMinute.parse(fields[1]); err != nil {
+		return nil, err
+	}
+	if s.hour, err = cronHour.parse(fields[2]); err != nil {
+		return nil, err
+	}
+	if s.dom, err = cronDom.parse(fields[3]); err != nil {
+		return nil, err
+	}
+	if s.month, err = cronMonth.parse(fields[4]); err != nil {
+		return nil, err
+	}
+	if s.dow, err = cronDow.parse(fields[5]); err != nil {
+		return nil, err
+	}
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	s.hourAny = cronAny(fields[2])
+	s.domAny = cronAny(fields[3])
+	s.dowAny = cronAny(fields[5])
+	return s, nil
+}
+
+// cronAny 字段是否以*或?开头(包括*/2这样带步长的写法)，与标准cron一致，这时日和星期需同时满足
+func cronAny(field string) bool {
+	return strings.HasPrefix(field, "*") || strings.HasPrefix(field, "?")
+}
+
+// parse 解析单个字段，返回按位表示的取值集合
+func (f cronField) parse(field string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepPart); err != nil || step <= 0 {
+				return 0, fmt.Errorf("cron表达式%s字段的步长%q错误", f.name, stepPart)
+			}
+		}
+
+		var start, end int
+		switch {
+		case rangePart == "*" || rangePart == "?":
+			start, end = f.min, f.max
+		case strings.Contains(rangePart, "-"):
+			low, high, _ := strings.Cut(rangePart, "-")
+			var err error
+			if start, err = f.value(low); err != nil {
+				return 0, err
+			}
+			if end, err = f.value(high); err != nil {
+				return 0, err
+			}
+			if start > end {
+				return 0, fmt.Errorf("cron表达式%s字段的范围%q错误", f.name, rangePart)
+			}
+		default:
+			var err error
+			if start, err = f.value(rangePart); err != nil {
+				return 0, err
+			}
+			// 单个值带步长时表示从该值到最大值
+			end = start
+			if hasStep {
+				end = f.max
+			}
+		}
+
+		for v := start; v <= end; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func (f cronField) value(s string) (int, error) {
+	if v, ok := f.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("cron表达式%s字段的值%q错误，应在%d到%d之间", f.name, s, f.min, f.max)
+	}
+	return v, nil
+}
+
+// Next 返回t之后的下一次执行时间，找不到时返回零值
+// 夏令时切换时与标准cron一致：小时不以*开头的任务，在跳过的时间内应执行的在切换后立即执行一次，重复的时间只执行一次
+func (s *CronSchedule) Next(t time.Time) time.Time {
+	origin := t.Location()
+	t = t.In(s.location).Truncate(time.Second)
+	start := wallClock(t)
+	t = t.Add(time.Second)
+	limit := t.AddDate(cronMaxYears, 0, 0)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, s.location)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, s.location)
+			continue
+		}
+
+		// 时、分、秒按实际经过的时间推进
+		prev := t
+		switch {
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			t = t.Add(time.Hour - time.Duration(t.Minute())*time.Minute - time.Duration(t.Second())*time.Second)
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute - time.Duration(t.Second())*time.Second)
+		case s.second&(1<<uint(t.Second())) == 0:
+			t = t.Add(time.Second)
+		case !s.hourAny && !wallClock(t).After(start):
+			// 夏令时结束时重复的时间已经执行过
+			t = t.Add(time.Second)
+		default:
+			return t.In(origin)
+		}
+		if s.skippedMatch(prev, t) {
+			return t.In(origin)
+		}
+	}
+	return time.Time{}
+}
+
+// skippedMatch 判断从prev推进到next时，夏令时开始跳过的整点中是否有需要执行的时间
+func (s *CronSchedule) skippedMatch(prev, next time.Time) bool {
+	if s.hourAny || prev.YearDay() != next.YearDay() {
+		return false
+	}
+	for h := prev.Hour() + 1; h < next.Hour(); h++ {
+		if s.hour&(1<<uint(h)) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// wallClock 将当地时间转换为不含时区偏移的时间，用于比较当地时间的先后
+func wallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+}
+
+func (s *CronSchedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domAny || s.dowAny {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
@@ -0,0 +1,237 @@
+package util
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronScheduleNext(t *testing.T) {
+	utc := time.UTC
+	date := func(year int, month time.Month, day, hour, min, sec int) time.Time {
+		return time.Date(year, month, day, hour, min, sec, 0, utc)
+	}
+
+	tests := []struct {
+		name string
+		expr string
+		from time.Time
+		want []time.Time
+	}{
+		{
+			name: "5个字段",
+			expr: "*/15 * * * *",
+			from: date(2026, 1, 1, 10, 7, 30),
+			want: []time.Time{date(2026, 1, 1, 10, 15, 0), date(2026, 1, 1, 10, 30, 0), date(2026, 1, 1, 10, 45, 0)},
+		},
+		{
+			name: "6个字段带秒",
+			expr: "*/20 * * * * *",
+			from: date(2026, 1, 1, 10, 0, 5),
+			want: []time.Time{date(2026, 1, 1, 10, 0, 20), date(2026, 1, 1, 10, 0, 40), date(2026, 1, 1, 10, 1, 0)},
+		},
+		{
+			name: "范围带步长",
+			expr: "0 9-17/4 * * *",
+			from: date(2026, 1, 1, 8, 0, 0),
+			want: []time.Time{date(2026, 1, 1, 9, 0, 0), date(2026, 1, 1, 13, 0, 0), date(2026, 1, 1, 17, 0, 0)},
+		},
+		{
+			name: "单个值带步长",
+			expr: "5/20 * * * *",
+			from: date(2026, 1, 1, 10, 0, 0),
+			want: []time.Time{date(2026, 1, 1, 10, 5, 0), date(2026, 1, 1, 10, 25, 0), date(2026, 1, 1, 10, 45, 0)},
+		},
+		{
+			name: "列表",
+			expr: "30 8 * * 1,3,5",
+			from: date(2026, 1, 1, 0, 0, 0), // 周四
+			want: []time.Time{date(2026, 1, 2, 8, 30, 0), date(2026, 1, 5, 8, 30, 0), date(2026, 1, 7, 8, 30, 0)},
+		},
+		{
+			name: "月份名称",
+			expr: "0 0 1 jan,JUL *",
+			from: date(2026, 3, 1, 0, 0, 0),
+			want: []time.Time{date(2026, 7, 1, 0, 0, 0), date(2027, 1, 1, 0, 0, 0), date(2027, 7, 1, 0, 0, 0)},
+		},
+		{
+			name: "星期名称范围",
+			expr: "0 12 * * MON-fri",
+			from: date(2026, 1, 3, 0, 0, 0), // 周六
+			want: []time.Time{date(2026, 1, 5, 12, 0, 0), date(2026, 1, 6, 12, 0, 0), date(2026, 1, 7, 12, 0, 0)},
+		},
+		{
+			name: "7表示周日",
+			expr: "0 0 * * 7",
+			from: date(2026, 1, 1, 0, 0, 0),
+			want: []time.Time{date(2026, 1, 4, 0, 0, 0), date(2026, 1, 11, 0, 0, 0), date(2026, 1, 18, 0, 0, 0)},
+		},
+		{
+			name: "星期范围包含7",
+			expr: "0 0 * * 5-7",
+			from: date(2026, 1, 1, 0, 0, 0),
+			want: []time.Time{date(2026, 1, 2, 0, 0, 0), date(2026, 1, 3, 0, 0, 0), date(2026, 1, 4, 0, 0, 0)},
+		},
+		{
+			name: "日和星期都有限制时满足其一",
+			expr: "0 0 13 * 5",
+			from: date(2026, 1, 1, 0, 0, 0),
+			want: []time.Time{date(2026, 1, 2, 0, 0, 0), date(2026, 1, 9, 0, 0, 0), date(2026, 1, 13, 0, 0, 0)},
+		},
+		{
+			name: "日为*带步长时日和星期都要满足",
+			expr: "0 0 */2 * 1",
+			from: date(2026, 1, 1, 0, 0, 0),
+			want: []time.Time{date(2026, 1, 5, 0, 0, 0), date(2026, 1, 19, 0, 0, 0), date(2026, 2, 9, 0, 0, 0)},
+		},
+		{
+			name: "星期为*带步长时日和星期都要满足",
+			expr: "0 0 1 * */3",
+			from: date(2026, 1, 1, 0, 0, 0),
+			want: []time.Time{date(2026, 2, 1, 0, 0, 0), date(2026, 3, 1, 0, 0, 0), date(2026, 4, 1, 0, 0, 0)},
+		},
+		{
+			name: "问号",
+			expr: "0 0 ? * SUN",
+			from: date(2026, 1, 1, 0, 0, 0),
+			want: []time.Time{date(2026, 1, 4, 0, 0, 0), date(2026, 1, 11, 0, 0, 0), date(2026, 1, 18, 0, 0, 0)},
+		},
+		{
+			name: "预定义表达式",
+			expr: "@monthly",
+			from: date(2026, 1, 15, 0, 0, 0),
+			want: []time.Time{date(2026, 2, 1, 0, 0, 0), date(2026, 3, 1, 0, 0, 0), date(2026, 4, 1, 0, 0, 0)},
+		},
+		{
+			name: "闰年2月29日",
+			expr: "0 0 29 2 *",
+			from: date(2026, 1, 1, 0, 0, 0),
+			want: []time.Time{date(2028, 2, 29, 0, 0, 0), date(2032, 2, 29, 0, 0, 0), date(2036, 2, 29, 0, 0, 0)},
+		},
+		{
+			name: "2月30日永远不会执行",
+			expr: "0 0 30 2 *",
+			from: date(2026, 1, 1, 0, 0, 0),
+			want: []time.Time{{}},
+		},
+		{
+			name: "4月31日永远不会执行",
+			expr: "0 0 31 4 *",
+			from: date(2026, 1, 1, 0, 0, 0),
+			want: []time.Time{{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := ParseCron(tt.expr, utc)
+			if err != nil {
+				t.Fatalf("ParseCron(%q) error = %v", tt.expr, err)
+			}
+			next := tt.from
+			for i, want := range tt.want {
+				next = schedule.Next(next)
+				if !next.Equal(want) {
+					t.Fatalf("第%d次执行时间 = %v, want %v", i+1, next, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCronScheduleNextDST(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("加载时区失败: %v", err)
+	}
+	date := func(year int, month time.Month, day, hour, min int) time.Time {
+		return time.Date(year, month, day, hour, min, 0, 0, ny)
+	}
+	utc := func(year int, month time.Month, day, hour, min int) time.Time {
+		return time.Date(year, month, day, hour, min, 0, 0, time.UTC)
+	}
+
+	// 2026-03-08 02:00 EST 调整为 03:00 EDT，2026-11-01 02:00 EDT 调整为 01:00 EST
+	tests := []struct {
+		name string
+		expr string
+		from time.Time
+		want []time.Time
+	}{
+		{
+			name: "跳过的时间在切换后立即执行",
+			expr: "30 2 * * *",
+			from: date(2026, 3, 7, 12, 0),
+			want: []time.Time{date(2026, 3, 8, 3, 0), date(2026, 3, 9, 2, 30)},
+		},
+		{
+			name: "跳过的时间之后的执行时间不变",
+			expr: "0 3 * * *",
+			from: date(2026, 3, 7, 12, 0),
+			want: []time.Time{date(2026, 3, 8, 3, 0), date(2026, 3, 9, 3, 0)},
+		},
+		{
+			name: "小时为*时按经过的时间执行",
+			expr: "*/30 * * * *",
+			from: date(2026, 3, 8, 1, 0),
+			want: []time.Time{date(2026, 3, 8, 1, 30), date(2026, 3, 8, 3, 0), date(2026, 3, 8, 3, 30)},
+		},
+		{
+			name: "重复的时间只执行一次",
+			expr: "30 1 * * *",
+			from: date(2026, 10, 31, 12, 0),
+			want: []time.Time{utc(2026, 11, 1, 5, 30), date(2026, 11, 2, 1, 30)},
+		},
+		{
+			name: "重复的时间内按小时范围执行",
+			expr: "0 1-3 * * *",
+			from: date(2026, 11, 1, 0, 30),
+			want: []time.Time{utc(2026, 11, 1, 5, 0), utc(2026, 11, 1, 7, 0), utc(2026, 11, 1, 8, 0)},
+		},
+		{
+			name: "小时为*时重复的时间都执行",
+			expr: "0 * * * *",
+			from: date(2026, 11, 1, 0, 30),
+			want: []time.Time{utc(2026, 11, 1, 5, 0), utc(2026, 11, 1, 6, 0), utc(2026, 11, 1, 7, 0)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := ParseCron(tt.expr, ny)
+			if err != nil {
+				t.Fatalf("ParseCron(%q) error = %v", tt.expr, err)
+			}
+			// 从UTC时间计算，验证使用配置的时区而不是参数的时区
+			next := tt.from.UTC()
+			for i, want := range tt.want {
+				next = schedule.Next(next)
+				if !next.Equal(want) {
+					t.Fatalf("第%d次执行时间 = %v, want %v", i+1, next.In(ny), want.In(ny))
+				}
+			}
+		})
+	}
+}
+
+func TestParseCronError(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * *",
+		"* * * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"*/x * * * *",
+		"* * * foo *",
+		"* * * * funday",
+		"@every 5m",
+	} {
+		if _, err := ParseCron(expr, time.UTC); err == nil {
+			t.Errorf("ParseCron(%q) 应返回错误", expr)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package util
 
 import (
+	"fmt"
 	"time"
 )
 
@@ -23,3 +24,27 @@ func GetNow() time.Time {
 	}
 	return time.Now()
 }
+
+// FormatDuration 格式化时长，如 1天2小时3分4秒
+func FormatDuration(d time.Duration) string {
+	seconds := int64(d.Seconds())
+	if seconds < 0 {
+		seconds = 0
+	}
+
+	days := seconds / 86400
+	hours := seconds % 86400 / 3600
+	minutes := seconds % 3600 / 60
+	seconds = seconds % 60
+
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%d天%d小时%d分%d秒", days, hours, minutes, seconds)
+	case hours > 0:
+		return fmt.Sprintf("%d小时%d分%d秒", hours, minutes, seconds)
+	case minutes > 0:
+		return fmt.Sprintf("%d分%d秒", minutes, seconds)
+	default:
+		return fmt.Sprintf("%d秒", seconds)
+	}
+}
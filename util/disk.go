@@ -0,0 +1,180 @@
+package util
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/shirou/gopsutil/v3/disk"
+)
+
+// GiB 1GiB对应的字节数
+const GiB = 1 << 30
+
+// DiskStat 磁盘分区使用情况
+type DiskStat struct {
+	Fstype            string  `json:"fstype"`              // 文件系统类型
+	Total             uint64  `json:"total"`               // 总空间(字节)
+	Free              uint64  `json:"free"`                // 剩余空间(字节)
+	UsedPercent       float64 `json:"used_percent"`        // 空间使用率
+	InodesTotal       uint64  `json:"inodes_total"`        // inode总数
+	InodesFree        uint64  `json:"inodes_free"`         // 剩余inode数
+	InodesUsedPercent float64 `json:"inodes_used_percent"` // inode使用率
+}
+
+// NewDiskStat 根据gopsutil的分区使用情况创建DiskStat
+func NewDiskStat(usage *disk.UsageStat) DiskStat {
+	return DiskStat{
+		Fstype:            usage.Fstype,
+		Total:             usage.Total,
+		Free:              usage.Free,
+		UsedPercent:       usage.UsedPercent,
+		InodesTotal:       usage.InodesTotal,
+		InodesFree:        usage.InodesFree,
+		InodesUsedPercent: usage.InodesUsedPercent,
+	}
+}
+
+// DiskFilter 磁盘分区过滤规则，挂载点和文件系统类型均支持通配符(如 /data*、cgroup*)
+// Include为空时不限制，同时匹配Include和Exclude时以Exclude为准
+type DiskFilter struct {
+	IncludeMounts  []string `json:"include_mounts"`  // 只监控匹配的挂载点
+	ExcludeMounts  []string `json:"exclude_mounts"`  // 排除匹配的挂载点
+	IncludeFstypes []string `json:"include_fstypes"` // 只监控匹配的文件系统类型
+	ExcludeFstypes []string `json:"exclude_fstypes"` // 排除匹配的文件系统类型
+}
+
+// DefaultDiskFilter 默认过滤规则，排除常见的虚拟文件系统
+func DefaultDiskFilter() DiskFilter {
+	return DiskFilter{
+		ExcludeMounts:  []string{"/dev", "/dev/*", "/sys", "/sys/*", "/proc", "/proc/*"},
+		ExcludeFstypes: []string{"tmpfs*", "sysfs*", "proc*", "devtmpfs*", "cgroup*"},
+	}
+}
+
+// Match 判断分区是否需要监控
+func (f DiskFilter) Match(mountpoint, fstype string) bool {
+	if len(f.IncludeMounts) > 0 && !matchAny(f.IncludeMounts, mountpoint) {
+		return false
+	}
+	if len(f.IncludeFstypes) > 0 && !matchAny(f.IncludeFstypes, fstype) {
+		return false
+	}
+	return !matchAny(f.ExcludeMounts, mountpoint) && !matchAny(f.ExcludeFstypes, fstype)
+}
+
+// Validate 校验过滤规则中的通配符
+func (f DiskFilter) Validate() error {
+	for _, patterns := range [][]string{f.IncludeMounts, f.ExcludeMounts, f.IncludeFstypes, f.ExcludeFstypes} {
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("磁盘过滤规则%s格式错误: %v", pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+// DiskRule 挂载点阈值规则，未匹配任何规则的分区使用全局磁盘阈值
+// 匹配规则后该分区的所有磁盘阈值均以规则为准，值为0的阈值不启用
+type DiskRule struct {
+	Mountpoint             string  `json:"mountpoint"`               // 挂载点，支持通配符
+	Threshold              float64 `json:"threshold"`                // 使用率告警阈值(%)
+	CriticalThreshold      float64 `json:"critical_threshold"`       // 使用率严重阈值(%)
+	MinFreeGB              float64 `json:"min_free_gb"`              // 剩余空间告警阈值(GiB)
+	CriticalMinFreeGB      float64 `json:"critical_min_free_gb"`     // 剩余空间严重阈值(GiB)
+	InodeThreshold         float64 `json:"inode_threshold"`          // inode使用率告警阈值(%)
+	InodeCriticalThreshold float64 `json:"inode_critical_threshold"` // inode使用率严重阈值(%)
+}
+
+// Validate 校验挂载点阈值规则
+func (r DiskRule) Validate() error {
+	if r.Mountpoint == "" {
+		return fmt.Errorf("挂载点不能为空")
+	}
+	if _, err := path.Match(r.Mountpoint, ""); err != nil {
+		return fmt.Errorf("挂载点%s格式错误: %v", r.Mountpoint, err)
+	}
+	if r.CriticalThreshold > 0 && r.CriticalThreshold < r.Threshold {
+		return fmt.Errorf("挂载点%s严重阈值%.2f%%不能低于告警阈值%.2f%%", r.Mountpoint, r.CriticalThreshold, r.Threshold)
+	}
+	if r.InodeCriticalThreshold > 0 && r.InodeCriticalThreshold < r.InodeThreshold {
+		return fmt.Errorf("挂载点%s inode严重阈值%.2f%%不能低于告警阈值%.2f%%", r.Mountpoint, r.InodeCriticalThreshold, r.InodeThreshold)
+	}
+	return validateMinFree("挂载点"+r.Mountpoint, r.MinFreeGB, r.CriticalMinFreeGB)
+}
+
+// validateMinFree 校验剩余空间阈值，严重阈值应小于告警阈值
+func validateMinFree(name string, warningGB, criticalGB float64) error {
+	if warningGB < 0 || criticalGB < 0 {
+		return fmt.Errorf("%s剩余空间阈值不能为负数", name)
+	}
+	if warningGB > 0 && criticalGB > warningGB {
+		return fmt.Errorf("%s剩余空间严重阈值%.2fGiB不能高于告警阈值%.2fGiB", name, criticalGB, warningGB)
+	}
+	return nil
+}
+
+// DiskRuleFor 获取挂载点适用的阈值规则，按顺序使用第一条匹配的规则
+func (c MonitorConfig) DiskRuleFor(mountpoint string) DiskRule {
+	for _, rule := range c.DiskRules {
+		if matched, _ := path.Match(rule.Mountpoint, mountpoint); matched {
+			return rule
+		}
+	}
+	return DiskRule{
+		Mountpoint:             mountpoint,
+		Threshold:              c.DiskThreshold,
+		CriticalThreshold:      c.DiskCriticalThreshold,
+		MinFreeGB:              c.DiskMinFreeGB,
+		CriticalMinFreeGB:      c.DiskCriticalMinFreeGB,
+		InodeThreshold:         c.InodeThreshold,
+		InodeCriticalThreshold: c.InodeCriticalThreshold,
+	}
+}
+
+// diskSum 磁盘分区多次采样的累计值，用于计算平均值
+type diskSum struct {
+	usage      float64
+	free       uint64
+	inodeUsage float64
+	hasInodes  bool
+	count      int
+}
+
+func (s *diskSum) add(stat DiskStat) {
+	s.usage += stat.UsedPercent
+	s.free += stat.Free
+	s.inodeUsage += stat.InodesUsedPercent
+	// 部分文件系统(如btrfs)不提供inode数量
+	s.hasInodes = s.hasInodes || stat.InodesTotal > 0
+	s.count++
+}
+
+// checks 按阈值规则检查分区的使用率、剩余空间和inode使用率
+func (s *diskSum) checks(mountPoint string, rule DiskRule) []thresholdCheck {
+	count := float64(s.count)
+	checks := make([]thresholdCheck, 0, 3)
+	if rule.Threshold > 0 || rule.CriticalThreshold > 0 {
+		checks = append(checks, percentCheck("disk:"+mountPoint, fmt.Sprintf("磁盘%s分区使用率", mountPoint),
+			s.usage/count, rule.Threshold, rule.CriticalThreshold))
+	}
+	if rule.MinFreeGB > 0 || rule.CriticalMinFreeGB > 0 {
+		checks = append(checks, freeCheck("disk_free:"+mountPoint, fmt.Sprintf("磁盘%s分区剩余空间", mountPoint),
+			s.free/uint64(s.count), rule.MinFreeGB, rule.CriticalMinFreeGB))
+	}
+	if s.hasInodes && (rule.InodeThreshold > 0 || rule.InodeCriticalThreshold > 0) {
+		checks = append(checks, percentCheck("disk_inode:"+mountPoint, fmt.Sprintf("磁盘%s分区inode使用率", mountPoint),
+			s.inodeUsage/count, rule.InodeThreshold, rule.InodeCriticalThreshold))
+	}
+	return checks
+}
+
+// matchAny 判断值是否匹配任意一个通配符
+func matchAny(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, value); matched {
+			return true
+		}
+	}
+	return false
+}
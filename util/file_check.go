@@ -0,0 +1,160 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// FileCheckConfig 文件检查配置，file_age和dir_size类型使用
+type FileCheckConfig struct {
+	MaxAge       int     `json:"max_age"`       // file_age: 最新文件的最大时长(分钟)
+	MinSizeMB    float64 `json:"min_size_mb"`   // dir_size: 目录最小大小(MiB)，0表示不限制
+	MaxSizeMB    float64 `json:"max_size_mb"`   // dir_size: 目录最大大小(MiB)，0表示不限制
+	MinFiles     int     `json:"min_files"`     // dir_size: 最少文件数，0表示不限制
+	MaxFiles     int     `json:"max_files"`     // dir_size: 最多文件数，0表示不限制
+	MaxGrowthMB  float64 `json:"max_growth_mb"` // dir_size: 增长时间窗口内的最大增长(MiB)，0表示不限制
+	GrowthWindow int     `json:"growth_window"` // dir_size: 增长时间窗口(分钟)，默认60
+}
+
+// validateFileCheck 校验文件检查配置
+func validateFileCheck(c Check) error {
+	if c.Target == "" {
+		return fmt.Errorf("检查路径不能为空")
+	}
+	if _, err := filepath.Match(c.Target, ""); err != nil {
+		return fmt.Errorf("检查路径%s格式错误: %v", c.Target, err)
+	}
+
+	f := c.File
+	if c.Type == CheckTypeFileAge {
+		if f.MaxAge <= 0 {
+			return fmt.Errorf("文件最大时长必须大于0")
+		}
+		return nil
+	}
+
+	if f.MinSizeMB < 0 || f.MaxSizeMB < 0 || f.MinFiles < 0 || f.MaxFiles < 0 || f.MaxGrowthMB < 0 || f.GrowthWindow < 0 {
+		return fmt.Errorf("目录大小、文件数和增长限制不能为负数")
+	}
+	if f.MinSizeMB == 0 && f.MaxSizeMB == 0 && f.MinFiles == 0 && f.MaxFiles == 0 && f.MaxGrowthMB == 0 {
+		return fmt.Errorf("至少需要设置目录大小、文件数或增长限制中的一项")
+	}
+	if f.MaxSizeMB > 0 && f.MaxSizeMB < f.MinSizeMB {
+		return fmt.Errorf("目录最大大小不能小于最小大小")
+	}
+	if f.MaxFiles > 0 && f.MaxFiles < f.MinFiles {
+		return fmt.Errorf("最多文件数不能小于最少文件数")
+	}
+	return nil
+}
+
+// runFileAgeCheck 检查匹配的最新文件的修改时间，返回最新文件已存在的秒数
+func runFileAgeCheck(c Check, now time.Time) (float64, error) {
+	matches, err := filepath.Glob(c.Target)
+	if err != nil {
+		return 0, fmt.Errorf("检查路径%s格式错误: %v", c.Target, err)
+	}
+
+	var newest string
+	var newestTime time.Time
+	for _, match := range matches {
+		info, err := os.Stat(match)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		if info.ModTime().After(newestTime) {
+			newest, newestTime = match, info.ModTime()
+		}
+	}
+	if newest == "" {
+		return 0, fmt.Errorf("没有匹配%s的文件", c.Target)
+	}
+
+	age := now.Sub(newestTime)
+	if age > time.Duration(c.File.MaxAge)*time.Minute {
+		return age.Seconds(), fmt.Errorf("最新文件%s修改于%s，已超过%d分钟未更新",
+			newest, newestTime.Format("2006-01-02 15:04:05"), c.File.MaxAge)
+	}
+	return age.Seconds(), nil
+}
+
+// runDirSizeCheck 统计目录下所有文件的大小和数量，返回目录大小(字节)
+func runDirSizeCheck(ctx context.Context, c Check) (float64, error) {
+	var size int64
+	var count int
+	err := filepath.WalkDir(c.Target, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return fmt.Errorf("统计超时")
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			// 统计期间被删除的文件忽略
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		size += info.Size()
+		count++
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("统计目录%s失败: %v", c.Target, err)
+	}
+
+	f := c.File
+	sizeMB := float64(size) / MiB
+	var breaches []string
+	if f.MinSizeMB > 0 && sizeMB < f.MinSizeMB {
+		breaches = append(breaches, fmt.Sprintf("目录大小%.2fMiB小于%.2fMiB", sizeMB, f.MinSizeMB))
+	}
+	if f.MaxSizeMB > 0 && sizeMB > f.MaxSizeMB {
+		breaches = append(breaches, fmt.Sprintf("目录大小%.2fMiB超过%.2fMiB", sizeMB, f.MaxSizeMB))
+	}
+	if f.MinFiles > 0 && count < f.MinFiles {
+		breaches = append(breaches, fmt.Sprintf("文件数%d少于%d", count, f.MinFiles))
+	}
+	if f.MaxFiles > 0 && count > f.MaxFiles {
+		breaches = append(breaches, fmt.Sprintf("文件数%d超过%d", count, f.MaxFiles))
+	}
+	if len(breaches) > 0 {
+		return float64(size), fmt.Errorf("%s", strings.Join(breaches, "，"))
+	}
+	return float64(size), nil
+}
+
+// GrowthWindow 目录增长时间窗口，未设置增长限制时返回0
+func (c Check) GrowthWindow() time.Duration {
+	if c.Type != CheckTypeDirSize || c.File.MaxGrowthMB <= 0 {
+		return 0
+	}
+	if c.File.GrowthWindow <= 0 {
+		return 60 * time.Minute
+	}
+	return time.Duration(c.File.GrowthWindow) * time.Minute
+}
+
+// CheckGrowth 与时间窗口内最早的检查结果比较目录大小，增长超过限制时将检查结果标记为失败
+func CheckGrowth(c Check, result *CheckResult, earliest *CheckResult) {
+	if !result.Success || earliest == nil || c.GrowthWindow() == 0 {
+		return
+	}
+
+	growthMB := (result.Value - earliest.Value) / MiB
+	if growthMB > c.File.MaxGrowthMB {
+		result.Success = false
+		result.Message = fmt.Sprintf("目录在%s内增长%.2fMiB，超过%.2fMiB",
+			time.Unix(result.Timestamp, 0).Sub(time.Unix(earliest.Timestamp, 0)).Round(time.Second), growthMB, c.File.MaxGrowthMB)
+	}
+}
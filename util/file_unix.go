@@ -0,0 +1,24 @@
+//go:build !windows
+
+package util
+
+import (
+	"os"
+	"syscall"
+)
+
+// fileInode 获取文件inode
+func fileInode(info os.FileInfo) uint64 {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return uint64(stat.Ino)
+	}
+	return 0
+}
+
+// fileOwner 获取文件所属用户和用户组ID
+func fileOwner(info os.FileInfo) (int, int) {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return int(stat.Uid), int(stat.Gid)
+	}
+	return 0, 0
+}
@@ -0,0 +1,13 @@
+package util
+
+import "os"
+
+// fileInode Windows下不支持inode，只能通过文件截断判断日志轮转
+func fileInode(info os.FileInfo) uint64 {
+	return 0
+}
+
+// fileOwner Windows下不支持获取文件所属用户
+func fileOwner(info os.FileInfo) (int, int) {
+	return 0, 0
+}
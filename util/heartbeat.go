@@ -0,0 +1,203 @@
+package util
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AlertSourceHeartbeat 心跳监控告警来源
+const AlertSourceHeartbeat = "heartbeat"
+
+// 心跳ping类型
+const (
+	HeartbeatPingStart   = "start"   // 任务开始
+	HeartbeatPingSuccess = "success" // 任务成功，未指定类型的ping视为成功
+	HeartbeatPingFail    = "fail"    // 任务失败
+)
+
+// 心跳状态
+const (
+	HeartbeatStatusNew     = "new"     // 创建后尚未收到ping且未超时
+	HeartbeatStatusUp      = "up"      // 正常
+	HeartbeatStatusRunning = "running" // 任务已开始，尚未完成
+	HeartbeatStatusDown    = "down"    // 超时未收到ping
+	HeartbeatStatusFailed  = "failed"  // 任务报告失败
+)
+
+// Heartbeat 心跳监控配置，外部任务定时请求ping地址，超时未请求或报告失败时告警
+type Heartbeat struct {
+	ID           int    `json:"id"`
+	Name         string `json:"name"`
+	Token        string `json:"token"`  // ping地址中的令牌，新增时自动生成
+	Period       int    `json:"period"` // 预期的ping间隔(秒)
+	Grace        int    `json:"grace"`  // 宽限时间(秒)，超过间隔加宽限时间未收到ping时告警，任务开始后超过宽限时间未完成时告警
+	Severity     string `json:"severity"`
+	Enabled      bool   `json:"enabled"`
+	LastPingAt   int64  `json:"last_ping_at"`   // 最近一次ping时间
+	LastPingKind string `json:"last_ping_kind"` // 最近一次ping类型
+	CreatedAt    string `json:"created_at"`
+}
+
+// HeartbeatPing 心跳ping记录
+type HeartbeatPing struct {
+	ID          int    `json:"id"`
+	HeartbeatID int    `json:"heartbeat_id"`
+	Kind        string `json:"kind"`
+	Body        string `json:"body"` // 请求内容，可用于记录任务日志
+	RemoteAddr  string `json:"remote_addr"`
+	Timestamp   int64  `json:"timestamp"`
+}
+
+// DefaultHeartbeat 默认心跳监控配置：每天一次，宽限1小时
+func DefaultHeartbeat() Heartbeat {
+	return Heartbeat{Period: 86400, Grace: 3600, Enabled: true}
+}
+
+// NewHeartbeatToken 生成随机的ping令牌
+func NewHeartbeatToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("生成令牌失败: %v", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// ValidHeartbeatPingKind 判断ping类型是否有效
+func ValidHeartbeatPingKind(kind string) bool {
+	return kind == HeartbeatPingStart || kind == HeartbeatPingSuccess || kind == HeartbeatPingFail
+}
+
+// Validate 校验心跳监控配置
+func (h Heartbeat) Validate() error {
+	if h.Name == "" {
+		return fmt.Errorf("心跳名称不能为空")
+	}
+	if h.Period <= 0 {
+		return fmt.Errorf("ping间隔必须大于0")
+	}
+	if h.Grace < 0 {
+		return fmt.Errorf("宽限时间不能为负数")
+	}
+	if h.Severity != "" && !ValidSeverity(h.Severity) {
+		return fmt.Errorf("告警级别错误: %s", h.Severity)
+	}
+	return nil
+}
+
+// Status 心跳当前状态
+func (h Heartbeat) Status(now time.Time) string {
+	lastPing := time.Unix(h.LastPingAt, 0)
+	grace := time.Duration(h.Grace) * time.Second
+	switch {
+	case h.LastPingAt == 0:
+		// 创建后超过间隔加宽限时间仍未收到ping视为超时
+		if created, ok := h.createdTime(); ok && now.After(created.Add(time.Duration(h.Period)*time.Second+grace)) {
+			return HeartbeatStatusDown
+		}
+		return HeartbeatStatusNew
+	case h.LastPingKind == HeartbeatPingFail:
+		return HeartbeatStatusFailed
+	case h.LastPingKind == HeartbeatPingStart:
+		if now.After(lastPing.Add(grace)) {
+			return HeartbeatStatusDown
+		}
+		return HeartbeatStatusRunning
+	case now.After(lastPing.Add(time.Duration(h.Period)*time.Second + grace)):
+		return HeartbeatStatusDown
+	default:
+		return HeartbeatStatusUp
+	}
+}
+
+// createdTime 解析创建时间，数据库中为UTC时间
+func (h Heartbeat) createdTime() (time.Time, bool) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05"} {
+		if t, err := time.Parse(layout, h.CreatedAt); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// HeartbeatMonitor 心跳告警处理器
+type HeartbeatMonitor struct {
+	notifier CheckNotifier
+}
+
+// NewHeartbeatMonitor 创建心跳告警处理器
+func NewHeartbeatMonitor(tracker *AlertTracker, alertFunc func(Alert) error) *HeartbeatMonitor {
+	return &HeartbeatMonitor{
+		notifier: CheckNotifier{
+			Source:    AlertSourceHeartbeat,
+			Title:     "心跳监控告警",
+			Tracker:   tracker,
+			AlertFunc: alertFunc,
+		},
+	}
+}
+
+// Check 根据心跳状态发送告警或恢复通知，超时和报告失败分别告警，lastBody为最近一次ping的请求内容
+func (m *HeartbeatMonitor) Check(h Heartbeat, lastBody string, now time.Time) error {
+	status := h.Status(now)
+	severity := h.Severity
+	if severity == "" {
+		severity = SeverityCritical
+	}
+	lastPing := time.Unix(h.LastPingAt, 0).Format("2006-01-02 15:04:05")
+
+	down := thresholdCheck{Key: strconv.Itoa(h.ID) + ":down", Name: "心跳" + h.Name, Value: "状态" + status}
+	if status == HeartbeatStatusDown {
+		down.Severity = severity
+		switch {
+		case h.LastPingAt == 0:
+			created, _ := h.createdTime()
+			down.Breach = fmt.Sprintf("%s创建后一直未收到ping，创建时间: %s，预期间隔%d秒，宽限%d秒", down.Name, created.Local().Format("2006-01-02 15:04:05"), h.Period, h.Grace)
+		case h.LastPingKind == HeartbeatPingStart:
+			down.Breach = fmt.Sprintf("%s任务于%s开始，超过%d秒未完成", down.Name, lastPing, h.Grace)
+		default:
+			down.Breach = fmt.Sprintf("%s超时未收到ping，最近一次ping: %s，预期间隔%d秒，宽限%d秒", down.Name, lastPing, h.Period, h.Grace)
+		}
+	}
+	checks := []thresholdCheck{down}
+
+	// 任务重新开始时保持失败告警，直到任务成功
+	if status != HeartbeatStatusRunning {
+		failed := thresholdCheck{Key: strconv.Itoa(h.ID) + ":fail", Name: "心跳" + h.Name, Value: "状态" + status}
+		if status == HeartbeatStatusFailed {
+			failed.Severity = severity
+			failed.Breach = fmt.Sprintf("%s报告任务失败，时间: %s", failed.Name, lastPing)
+			if body := strings.TrimSpace(lastBody); body != "" {
+				failed.Breach += "\n任务日志:\n" + truncate(body, 1000)
+			}
+		}
+		checks = append(checks, failed)
+	}
+
+	var errs []string
+	for _, check := range checks {
+		if err := m.notifier.Update(check, now); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// Prune 已删除或停用的心跳处于告警状态时发送恢复通知
+func (m *HeartbeatMonitor) Prune(active []Heartbeat) error {
+	ids := make(map[string]bool, len(active))
+	for _, h := range active {
+		ids[strconv.Itoa(h.ID)] = true
+	}
+
+	return m.notifier.Prune(func(key string) bool {
+		id, _, _ := strings.Cut(key, ":")
+		return ids[id]
+	}, "心跳监控已删除或停用")
+}
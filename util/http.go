@@ -1 +1,59 @@
 package util
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// 默认HTTP请求超时时间(秒)
+const defaultHTTPTimeout = 10
+
+// NewHTTPClient 创建HTTP客户端
+// timeout 超时时间(秒)，小于等于0时使用默认值；insecure 为true时跳过TLS证书校验
+func NewHTTPClient(timeout int, insecure bool) *http.Client {
+	if timeout <= 0 {
+		timeout = defaultHTTPTimeout
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if insecure {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+
+	return &http.Client{
+		Timeout:   time.Duration(timeout) * time.Second,
+		Transport: transport,
+	}
+}
+
+// DoHTTPRequest 发送HTTP请求，响应状态码不是2xx时返回错误
+func DoHTTPRequest(client *http.Client, req *http.Request) ([]byte, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("读取响应失败: %v", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return body, fmt.Errorf("响应状态码%d: %s", resp.StatusCode, truncate(string(body), 200))
+	}
+
+	return body, nil
+}
+
+// truncate 截断字符串到指定长度(按字符计算)
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n]) + "..."
+}
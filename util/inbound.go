@@ -0,0 +1,129 @@
+package util
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// internalAlertSources 内部告警来源，外部系统的来源名称不能与其重复
+var internalAlertSources = map[string]bool{
+	AlertSourceMonitor:   true,
+	AlertSourceScript:    true,
+	AlertSourceProcess:   true,
+	AlertSourceCheck:     true,
+	AlertSourceLog:       true,
+	AlertSourceIntegrity: true,
+	AlertSourceHeartbeat: true,
+	AlertSourceSyslog:    true,
+}
+
+// APIKey 外部系统推送告警使用的API密钥，每个密钥对应一个告警来源
+type APIKey struct {
+	ID         int    `json:"id"`
+	Source     string `json:"source"`       // 告警来源
+	Prefix     string `json:"prefix"`       // 密钥前缀，用于识别密钥
+	Enabled    bool   `json:"enabled"`      // 是否启用
+	LastUsedAt int64  `json:"last_used_at"` // 最近一次使用时间
+	CreatedAt  string `json:"created_at"`
+}
+
+// Validate 校验API密钥配置
+func (k APIKey) Validate() error {
+	if k.Source == "" {
+		return fmt.Errorf("告警来源不能为空")
+	}
+	if strings.Contains(k.Source, ":") {
+		return fmt.Errorf("告警来源不能包含冒号")
+	}
+	if internalAlertSources[k.Source] {
+		return fmt.Errorf("告警来源%s为内部保留名称", k.Source)
+	}
+	return nil
+}
+
+// NewAPIKeySecret 生成随机的API密钥
+func NewAPIKeySecret() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("生成密钥失败: %v", err)
+	}
+	return "wn_" + hex.EncodeToString(b), nil
+}
+
+// HashAPIKey 计算API密钥的哈希，数据库中只保存哈希
+func HashAPIKey(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// InboundAlert 外部系统推送的告警
+type InboundAlert struct {
+	Source      string            `json:"source"`      // 告警来源，为空时使用API密钥对应的来源
+	Title       string            `json:"title"`       // 告警标题
+	Message     string            `json:"message"`     // 告警内容
+	Severity    string            `json:"severity"`    // 告警级别，默认warning
+	Labels      map[string]string `json:"labels"`      // 告警标签
+	Annotations map[string]string `json:"annotations"` // 告警注解，如runbook地址等附加信息
+	DedupKey    string            `json:"dedup_key"`   // 去重标识，相同标识的告警归并为同一事件，为空时使用标题
+}
+
+// Validate 校验外部告警并补充默认值
+func (a *InboundAlert) Validate() error {
+	if a.Title == "" {
+		return fmt.Errorf("告警标题不能为空")
+	}
+	if a.Severity == "" {
+		a.Severity = SeverityWarning
+	}
+	if !ValidSeverity(a.Severity) {
+		return fmt.Errorf("告警级别错误: %s", a.Severity)
+	}
+	if a.DedupKey == "" {
+		a.DedupKey = a.Title
+	}
+	return nil
+}
+
+// InboundAlertKey 外部告警的告警条件标识
+func InboundAlertKey(source, dedupKey string) string {
+	return source + ":" + dedupKey
+}
+
+// Alert 转换为告警消息，标签和注解附加在告警内容中
+func (a InboundAlert) Alert(now time.Time) Alert {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s:\n时间: %s\n来源: %s\n", a.Title, now.Format("2006-01-02 15:04:05"), a.Source)
+	if a.Message != "" {
+		b.WriteString(a.Message + "\n")
+	}
+	writePairs(&b, "标签", a.Labels)
+	writePairs(&b, "注解", a.Annotations)
+
+	alert := NewAlert(a.Source, a.Title, a.Severity, b.String())
+	alert.Key = InboundAlertKey(a.Source, a.DedupKey)
+	alert.Labels = a.Labels
+	alert.Annotations = a.Annotations
+	alert.Timestamp = now.Unix()
+	return alert
+}
+
+// writePairs 按名称排序输出键值对
+func writePairs(b *strings.Builder, title string, pairs map[string]string) {
+	if len(pairs) == 0 {
+		return
+	}
+	names := make([]string, 0, len(pairs))
+	for name := range pairs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	b.WriteString(title + ":\n")
+	for _, name := range names {
+		fmt.Fprintf(b, "  %s=%s\n", name, pairs[name])
+	}
+}
@@ -0,0 +1,289 @@
+package util
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AlertSourceIntegrity 文件完整性监控告警来源
+const AlertSourceIntegrity = "integrity"
+
+const (
+	integrityMaxFiles    = 10000 // 单个监控最多扫描的文件数
+	integrityDiffEntries = 20    // 告警内容中每类变化最多列出的文件数
+)
+
+// IntegrityWatcher 文件完整性监控配置
+type IntegrityWatcher struct {
+	ID         int      `json:"id"`
+	Name       string   `json:"name"`
+	Paths      []string `json:"paths"`    // 监控路径，支持通配符，目录递归扫描
+	Excludes   []string `json:"excludes"` // 排除的路径，支持通配符，匹配完整路径或文件名
+	Interval   int      `json:"interval"` // 扫描间隔(分钟)
+	Severity   string   `json:"severity"` // 告警级别，默认critical
+	Enabled    bool     `json:"enabled"`
+	BaselineAt int64    `json:"baseline_at"` // 基线确认时间，为0时下次扫描建立基线
+	CreatedAt  string   `json:"created_at"`
+}
+
+// FileState 文件状态，目录和符号链接的Hash为空
+type FileState struct {
+	Path string `json:"path"`
+	Hash string `json:"hash"` // SHA-256
+	Size int64  `json:"size"`
+	Mode string `json:"mode"`
+	UID  int    `json:"uid"`
+	GID  int    `json:"gid"`
+}
+
+// FileChange 文件变化
+type FileChange struct {
+	Path    string   `json:"path"`
+	Changes []string `json:"changes"` // 变化内容，如 hash、mode
+}
+
+// IntegrityDiff 当前文件状态与基线的差异
+type IntegrityDiff struct {
+	Added    []string     `json:"added"`
+	Removed  []string     `json:"removed"`
+	Modified []FileChange `json:"modified"`
+}
+
+// DefaultIntegrityWatcher 默认文件完整性监控配置
+func DefaultIntegrityWatcher() IntegrityWatcher {
+	return IntegrityWatcher{Interval: 10, Enabled: true}
+}
+
+// Validate 校验文件完整性监控配置
+func (w IntegrityWatcher) Validate() error {
+	if w.Name == "" {
+		return fmt.Errorf("监控名称不能为空")
+	}
+	if len(w.Paths) == 0 {
+		return fmt.Errorf("监控路径不能为空")
+	}
+	for _, pattern := range append(append([]string{}, w.Paths...), w.Excludes...) {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("路径%s格式错误: %v", pattern, err)
+		}
+	}
+	if w.Interval <= 0 {
+		return fmt.Errorf("扫描间隔必须大于0")
+	}
+	if w.Severity != "" && !ValidSeverity(w.Severity) {
+		return fmt.Errorf("告警级别错误: %s", w.Severity)
+	}
+	return nil
+}
+
+// ScanIntegrity 扫描监控路径下所有文件的状态
+func ScanIntegrity(w IntegrityWatcher) (map[string]FileState, error) {
+	states := make(map[string]FileState)
+	for _, pattern := range w.Paths {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("路径%s格式错误: %v", pattern, err)
+		}
+		for _, match := range matches {
+			err = filepath.WalkDir(match, func(path string, d fs.DirEntry, err error) error {
+				if err != nil {
+					// 无权限读取的子目录跳过
+					if os.IsPermission(err) && path != match {
+						return nil
+					}
+					return err
+				}
+				if w.excluded(path) {
+					if d.IsDir() {
+						return filepath.SkipDir
+					}
+					return nil
+				}
+				if _, exists := states[path]; exists {
+					return nil
+				}
+				if len(states) >= integrityMaxFiles {
+					return fmt.Errorf("文件数超过%d个，请缩小监控范围", integrityMaxFiles)
+				}
+
+				state, err := newFileState(path)
+				if err != nil {
+					return err
+				}
+				states[path] = state
+				return nil
+			})
+			if err != nil {
+				return nil, fmt.Errorf("扫描%s失败: %v", match, err)
+			}
+		}
+	}
+	return states, nil
+}
+
+// excluded 判断路径是否被排除
+func (w IntegrityWatcher) excluded(path string) bool {
+	for _, pattern := range w.Excludes {
+		if matched, _ := filepath.Match(pattern, path); matched {
+			return true
+		}
+		if matched, _ := filepath.Match(pattern, filepath.Base(path)); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// newFileState 获取文件状态，只计算普通文件的哈希，无权限读取的文件不计算哈希
+func newFileState(path string) (FileState, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return FileState{}, err
+	}
+	state := FileState{Path: path, Mode: info.Mode().String()}
+	state.UID, state.GID = fileOwner(info)
+	if !info.Mode().IsRegular() {
+		return state, nil
+	}
+	state.Size = info.Size()
+
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsPermission(err) {
+			return state, nil
+		}
+		return FileState{}, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if state.Size, err = io.Copy(h, f); err != nil {
+		return FileState{}, err
+	}
+	state.Hash = hex.EncodeToString(h.Sum(nil))
+	return state, nil
+}
+
+// DiffIntegrity 比较当前文件状态与基线
+func DiffIntegrity(baseline, current map[string]FileState) IntegrityDiff {
+	diff := IntegrityDiff{Added: []string{}, Removed: []string{}, Modified: []FileChange{}}
+	for path, state := range current {
+		old, exists := baseline[path]
+		if !exists {
+			diff.Added = append(diff.Added, path)
+			continue
+		}
+
+		var changes []string
+		if state.Hash != old.Hash {
+			changes = append(changes, "内容")
+		}
+		if state.Size != old.Size {
+			changes = append(changes, fmt.Sprintf("大小 %d -> %d", old.Size, state.Size))
+		}
+		if state.Mode != old.Mode {
+			changes = append(changes, fmt.Sprintf("权限 %s -> %s", old.Mode, state.Mode))
+		}
+		if state.UID != old.UID || state.GID != old.GID {
+			changes = append(changes, fmt.Sprintf("所有者 %d:%d -> %d:%d", old.UID, old.GID, state.UID, state.GID))
+		}
+		if len(changes) > 0 {
+			diff.Modified = append(diff.Modified, FileChange{Path: path, Changes: changes})
+		}
+	}
+	for path := range baseline {
+		if _, exists := current[path]; !exists {
+			diff.Removed = append(diff.Removed, path)
+		}
+	}
+
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	sort.Slice(diff.Modified, func(i, j int) bool { return diff.Modified[i].Path < diff.Modified[j].Path })
+	return diff
+}
+
+// Empty 是否没有变化
+func (d IntegrityDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Modified) == 0
+}
+
+// String 格式化文件变化，用于告警内容
+func (d IntegrityDiff) String() string {
+	var b strings.Builder
+	writePaths := func(title string, paths []string) {
+		if len(paths) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "%s(%d个):\n", title, len(paths))
+		for _, path := range paths[:min(len(paths), integrityDiffEntries)] {
+			b.WriteString("  " + path + "\n")
+		}
+		if len(paths) > integrityDiffEntries {
+			fmt.Fprintf(&b, "  ...等%d个\n", len(paths))
+		}
+	}
+
+	writePaths("新增文件", d.Added)
+	writePaths("删除文件", d.Removed)
+	modified := make([]string, 0, len(d.Modified))
+	for _, change := range d.Modified {
+		modified = append(modified, change.Path+": "+strings.Join(change.Changes, ", "))
+	}
+	writePaths("修改文件", modified)
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// IntegrityMonitor 文件完整性告警处理器，文件与基线不一致时告警，重新确认基线或文件恢复后发送恢复通知
+type IntegrityMonitor struct {
+	notifier CheckNotifier
+}
+
+// NewIntegrityMonitor 创建文件完整性告警处理器
+func NewIntegrityMonitor(tracker *AlertTracker, alertFunc func(Alert) error) *IntegrityMonitor {
+	return &IntegrityMonitor{
+		notifier: CheckNotifier{
+			Source:    AlertSourceIntegrity,
+			Title:     "文件完整性告警",
+			Tracker:   tracker,
+			AlertFunc: alertFunc,
+		},
+	}
+}
+
+// Handle 处理一次扫描结果
+func (m *IntegrityMonitor) Handle(w IntegrityWatcher, diff IntegrityDiff, now time.Time) error {
+	check := thresholdCheck{
+		Key:   strconv.Itoa(w.ID),
+		Name:  fmt.Sprintf("文件完整性监控%s(%s)", w.Name, strings.Join(w.Paths, ", ")),
+		Value: "文件与基线一致",
+	}
+	if !diff.Empty() {
+		check.Value = fmt.Sprintf("新增%d个、删除%d个、修改%d个文件", len(diff.Added), len(diff.Removed), len(diff.Modified))
+		check.Severity = w.Severity
+		if check.Severity == "" {
+			check.Severity = SeverityCritical
+		}
+		check.Breach = fmt.Sprintf("%s文件与基线不一致，%s\n%s", check.Name, check.Value, diff.String())
+	}
+	return m.notifier.Update(check, now)
+}
+
+// Prune 已删除或停用的监控处于告警状态时发送恢复通知
+func (m *IntegrityMonitor) Prune(active []IntegrityWatcher) error {
+	ids := make(map[string]bool, len(active))
+	for _, w := range active {
+		ids[strconv.Itoa(w.ID)] = true
+	}
+
+	return m.notifier.Prune(func(key string) bool { return ids[key] }, "文件完整性监控已删除或停用")
+}
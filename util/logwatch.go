@@ -0,0 +1,403 @@
+package util
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AlertSourceLog 日志监控告警来源
+const AlertSourceLog = "log"
+
+const (
+	logMaxReadBytes = 4 << 20 // 每次最多读取的字节数，其余内容下次读取
+	logMaxLineLen   = 500     // 告警内容中单行日志的最大长度
+	logMaxMatches   = 100     // 每条规则在时间窗口内最多保留的匹配行数
+	logContextLines = 10      // 告警内容中附带的最近匹配行数
+)
+
+// LogWatcher 日志监控配置
+type LogWatcher struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Path      string    `json:"path"`  // 日志文件路径
+	Rules     []LogRule `json:"rules"` // 匹配规则
+	Enabled   bool      `json:"enabled"`
+	CreatedAt string    `json:"created_at"`
+}
+
+// LogRule 日志匹配规则，时间窗口内匹配次数达到阈值时告警
+type LogRule struct {
+	Name      string `json:"name"`
+	Pattern   string `json:"pattern"`   // 正则表达式
+	Threshold int    `json:"threshold"` // 匹配次数阈值，默认1
+	Window    int    `json:"window"`    // 时间窗口(秒)，默认60
+	Severity  string `json:"severity"`  // 告警级别，默认warning
+}
+
+// LogOffset 日志文件读取位置，重启后从该位置继续读取
+type LogOffset struct {
+	WatcherID int    `json:"watcher_id"`
+	Path      string `json:"path"`
+	Inode     uint64 `json:"inode"` // 文件inode，用于判断日志是否已轮转
+	Offset    int64  `json:"offset"`
+	UpdatedAt int64  `json:"updated_at"`
+}
+
+// Validate 校验日志监控配置，并为规则补充默认值
+func (w *LogWatcher) Validate() error {
+	if w.Name == "" {
+		return fmt.Errorf("监控名称不能为空")
+	}
+	if w.Path == "" {
+		return fmt.Errorf("日志文件路径不能为空")
+	}
+	if len(w.Rules) == 0 {
+		return fmt.Errorf("至少需要一条匹配规则")
+	}
+
+	names := make(map[string]bool, len(w.Rules))
+	for i := range w.Rules {
+		rule := &w.Rules[i]
+		if rule.Name == "" {
+			return fmt.Errorf("规则名称不能为空")
+		}
+		if names[rule.Name] {
+			return fmt.Errorf("规则名称%s重复", rule.Name)
+		}
+		names[rule.Name] = true
+		if _, err := regexp.Compile(rule.Pattern); err != nil {
+			return fmt.Errorf("规则%s正则格式错误: %v", rule.Name, err)
+		}
+		if rule.Threshold < 0 || rule.Window < 0 {
+			return fmt.Errorf("规则%s的匹配次数和时间窗口不能为负数", rule.Name)
+		}
+		if rule.Threshold == 0 {
+			rule.Threshold = 1
+		}
+		if rule.Window == 0 {
+			rule.Window = 60
+		}
+		if rule.Severity != "" && !ValidSeverity(rule.Severity) {
+			return fmt.Errorf("告警级别错误: %s", rule.Severity)
+		}
+	}
+	return nil
+}
+
+// MatchLogLines 返回各规则匹配的日志行，用于测试匹配规则
+func MatchLogLines(w LogWatcher, lines []string) (map[string][]string, error) {
+	result := make(map[string][]string, len(w.Rules))
+	for _, rule := range w.Rules {
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("规则%s正则格式错误: %v", rule.Name, err)
+		}
+		result[rule.Name] = make([]string, 0)
+		for _, line := range lines {
+			if re.MatchString(line) {
+				result[rule.Name] = append(result[rule.Name], line)
+			}
+		}
+	}
+	return result, nil
+}
+
+// ReadLastLines 读取文件末尾最多n行
+func ReadLastLines(path string, n int) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("打开日志文件失败: %v", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("获取日志文件信息失败: %v", err)
+	}
+	start := max(info.Size()-logMaxReadBytes, 0)
+	data, err := io.ReadAll(io.NewSectionReader(f, start, info.Size()-start))
+	if err != nil {
+		return nil, fmt.Errorf("读取日志文件失败: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	// 从文件中间开始读取时第一行可能不完整
+	if start > 0 && len(lines) > 0 {
+		lines = lines[1:]
+	}
+	return lines[max(len(lines)-n, 0):], nil
+}
+
+// logMatch 规则匹配到的日志行
+type logMatch struct {
+	Time time.Time
+	Line string
+}
+
+// logTailer 单个日志文件的读取状态
+type logTailer struct {
+	path    string
+	file    *os.File
+	inode   uint64
+	offset  int64
+	matches map[string][]logMatch // 各规则时间窗口内的匹配
+}
+
+// LogMonitor 跟踪日志文件新增内容，按规则匹配并告警
+type LogMonitor struct {
+	mu       sync.Mutex
+	tailers  map[int]*logTailer
+	offsets  map[int]LogOffset // 上次保存的读取位置，首次打开文件时使用
+	notifier CheckNotifier
+}
+
+// NewLogMonitor 创建日志监控器
+func NewLogMonitor(tracker *AlertTracker, alertFunc func(Alert) error) *LogMonitor {
+	return &LogMonitor{
+		tailers: make(map[int]*logTailer),
+		offsets: make(map[int]LogOffset),
+		notifier: CheckNotifier{
+			Source:    AlertSourceLog,
+			Title:     "日志监控告警",
+			Tracker:   tracker,
+			AlertFunc: alertFunc,
+		},
+	}
+}
+
+// Restore 恢复保存的读取位置，已打开的日志文件不受影响
+func (m *LogMonitor) Restore(offsets []LogOffset) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, o := range offsets {
+		m.offsets[o.WatcherID] = o
+	}
+}
+
+// Poll 读取日志文件新增内容并按规则告警，返回当前读取位置
+func (m *LogMonitor) Poll(w LogWatcher, now time.Time) (LogOffset, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t := m.tailers[w.ID]
+	// 修改日志路径后重新开始跟踪
+	if t != nil && t.path != w.Path {
+		t.close()
+		t = nil
+	}
+	if t == nil {
+		t = &logTailer{path: w.Path, offset: -1, matches: make(map[string][]logMatch)}
+		if saved, exists := m.offsets[w.ID]; exists && saved.Path == w.Path {
+			t.inode, t.offset = saved.Inode, saved.Offset
+		}
+		m.tailers[w.ID] = t
+	}
+
+	rules := make([]*regexp.Regexp, len(w.Rules))
+	for i, rule := range w.Rules {
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return t.position(w.ID), fmt.Errorf("规则%s正则格式错误: %v", rule.Name, err)
+		}
+		rules[i] = re
+	}
+
+	var errs []string
+	err := t.read(func(line string) {
+		for i, re := range rules {
+			if re.MatchString(line) {
+				name := w.Rules[i].Name
+				matches := append(t.matches[name], logMatch{Time: now, Line: truncate(line, logMaxLineLen)})
+				t.matches[name] = matches[max(len(matches)-max(w.Rules[i].Threshold, logMaxMatches), 0):]
+			}
+		}
+	})
+	if err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	for _, rule := range w.Rules {
+		if err := m.notifier.Update(t.ruleCheck(w, rule, now), now); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if len(errs) > 0 {
+		return t.position(w.ID), fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return t.position(w.ID), nil
+}
+
+// Prune 关闭已删除或停用的日志监控，已删除的规则处于告警状态时发送恢复通知
+func (m *LogMonitor) Prune(active []LogWatcher) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	keys := make(map[string]bool)
+	ids := make(map[int]bool, len(active))
+	for _, w := range active {
+		ids[w.ID] = true
+		for _, rule := range w.Rules {
+			keys[logRuleKey(w.ID, rule.Name)] = true
+		}
+	}
+	for id, t := range m.tailers {
+		if !ids[id] {
+			t.close()
+			delete(m.tailers, id)
+		}
+	}
+
+	return m.notifier.Prune(func(key string) bool { return keys[key] }, "日志监控规则已删除或停用")
+}
+
+// ruleCheck 统计规则在时间窗口内的匹配次数
+func (t *logTailer) ruleCheck(w LogWatcher, rule LogRule, now time.Time) thresholdCheck {
+	since := now.Add(-time.Duration(rule.Window) * time.Second)
+	matches := t.matches[rule.Name]
+	for len(matches) > 0 && matches[0].Time.Before(since) {
+		matches = matches[1:]
+	}
+	t.matches[rule.Name] = matches
+
+	check := thresholdCheck{
+		Key:   logRuleKey(w.ID, rule.Name),
+		Name:  fmt.Sprintf("日志%s(%s)规则%s", w.Name, w.Path, rule.Name),
+		Value: fmt.Sprintf("%d秒内匹配%d次", rule.Window, len(matches)),
+	}
+	if len(matches) < rule.Threshold {
+		return check
+	}
+
+	check.Severity = rule.Severity
+	if check.Severity == "" {
+		check.Severity = SeverityWarning
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s在%d秒内匹配%d次，达到%d次(%s)\n最近匹配的日志:", check.Name, rule.Window, len(matches), rule.Threshold, rule.Pattern)
+	for _, match := range matches[max(len(matches)-logContextLines, 0):] {
+		b.WriteString("\n  " + match.Line)
+	}
+	check.Breach = b.String()
+	return check
+}
+
+// read 读取新增的完整日志行，处理日志轮转和截断
+func (t *logTailer) read(handle func(line string)) error {
+	info, err := os.Stat(t.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// 文件之后创建时从头读取
+			if t.offset < 0 {
+				t.offset = 0
+			}
+			// 轮转后新文件尚未创建时读完原文件剩余内容
+			if t.file != nil {
+				return t.drain(handle)
+			}
+			return nil
+		}
+		return fmt.Errorf("获取日志文件信息失败: %v", err)
+	}
+	inode := fileInode(info)
+
+	if t.file != nil && inode != t.inode {
+		// 日志已轮转，读完原文件剩余内容后从头读取新文件
+		if err = t.drain(handle); err != nil {
+			return err
+		}
+		t.close()
+		t.offset = 0
+	}
+
+	if t.file == nil {
+		if t.file, err = os.Open(t.path); err != nil {
+			return fmt.Errorf("打开日志文件失败: %v", err)
+		}
+		switch {
+		case t.offset < 0:
+			// 首次监控时从文件末尾开始，不处理历史内容
+			t.offset = info.Size()
+		case inode != t.inode:
+			// 停止期间日志已轮转
+			t.offset = 0
+		}
+		t.inode = inode
+	}
+
+	// 日志被截断时从头读取
+	if info.Size() < t.offset {
+		t.offset = 0
+	}
+	_, err = t.readFrom(t.file, info.Size(), handle)
+	return err
+}
+
+// drain 读取原文件剩余的全部内容
+func (t *logTailer) drain(handle func(line string)) error {
+	for {
+		n, err := t.readFrom(t.file, -1, handle)
+		if err != nil || n == 0 {
+			return err
+		}
+	}
+}
+
+// readFrom 从当前位置读取到size处的完整行，size小于0时读取到文件末尾并包括最后不完整的一行
+func (t *logTailer) readFrom(f *os.File, size int64, handle func(line string)) (int64, error) {
+	drain := size < 0
+	if drain {
+		info, err := f.Stat()
+		if err != nil {
+			return 0, fmt.Errorf("获取日志文件信息失败: %v", err)
+		}
+		size = info.Size()
+	}
+	n := min(size-t.offset, logMaxReadBytes)
+	if n <= 0 {
+		return 0, nil
+	}
+
+	r := bufio.NewReader(io.NewSectionReader(f, t.offset, n))
+	var read int64
+	for {
+		line, err := r.ReadString('\n')
+		if err == io.EOF {
+			// 不完整的行等待下次读取，单行超过读取上限时直接处理
+			if line != "" && (drain || read == 0 && n == logMaxReadBytes) {
+				handle(strings.TrimRight(line, "\r"))
+				read += int64(len(line))
+			}
+			break
+		}
+		if err != nil {
+			return read, fmt.Errorf("读取日志文件失败: %v", err)
+		}
+		read += int64(len(line))
+		handle(strings.TrimRight(line, "\r\n"))
+	}
+	t.offset += read
+	return read, nil
+}
+
+func (t *logTailer) position(watcherID int) LogOffset {
+	return LogOffset{WatcherID: watcherID, Path: t.path, Inode: t.inode, Offset: max(t.offset, 0)}
+}
+
+func (t *logTailer) close() {
+	if t.file != nil {
+		t.file.Close()
+		t.file = nil
+	}
+}
+
+func logRuleKey(watcherID int, rule string) string {
+	return strconv.Itoa(watcherID) + ":" + rule
+}
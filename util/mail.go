@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net"
 	"net/smtp"
+	"strconv"
 	"time"
 )
 
@@ -16,6 +17,8 @@ type EmailConfig struct {
 	Password string `json:"password"`
 	From     string `json:"from"`
 	To       string `json:"to"`
+
+	MinSeverity string `json:"min_severity"` // 最低告警级别，为空时接收所有级别
 }
 
 // SendTestEmail 发送测试邮件
@@ -43,7 +46,7 @@ func SendEmail(config EmailConfig, subject, body string) error {
 	)
 
 	// 连接SMTP服务器
-	host := fmt.Sprintf("%s:%d", config.SMTPHost, config.SMTPPort)
+	host := net.JoinHostPort(config.SMTPHost, strconv.Itoa(config.SMTPPort))
 	auth := smtp.PlainAuth("", config.Username, config.Password, config.SMTPHost)
 
 	// TLS配置
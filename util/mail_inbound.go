@@ -0,0 +1,225 @@
+package util
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"regexp"
+	"slices"
+	"strings"
+
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+const (
+	mailMaxMessageLen = 2000 // 告警内容中邮件正文的最大长度
+	mailMaxPartDepth  = 5    // 解析嵌套multipart的最大层数
+)
+
+// MailInboundConfig 邮件接收配置，设备发送到指定地址的邮件转换为告警
+type MailInboundConfig struct {
+	Enabled    bool     `json:"enabled"`
+	Address    string   `json:"address"`     // 监听地址，如 :2525
+	Recipients []string `json:"recipients"`  // 接收的收件人地址，其他地址的邮件拒收
+	Severity   string   `json:"severity"`    // 告警级别，默认warning
+	MaxSizeKB  int      `json:"max_size_kb"` // 单封邮件最大大小(KB)
+}
+
+// DefaultMailInboundConfig 默认邮件接收配置
+func DefaultMailInboundConfig() MailInboundConfig {
+	return MailInboundConfig{
+		Address:    ":2525",
+		Recipients: []string{},
+		Severity:   SeverityWarning,
+		MaxSizeKB:  1024,
+	}
+}
+
+// Validate 校验邮件接收配置，收件人地址统一转为小写
+func (c *MailInboundConfig) Validate() error {
+	if _, _, err := net.SplitHostPort(c.Address); err != nil {
+		return fmt.Errorf("监听地址%s格式错误: %v", c.Address, err)
+	}
+	for i, rcpt := range c.Recipients {
+		addr, err := mail.ParseAddress(rcpt)
+		if err != nil {
+			return fmt.Errorf("收件人地址%s格式错误: %v", rcpt, err)
+		}
+		c.Recipients[i] = strings.ToLower(addr.Address)
+	}
+	if c.Enabled && len(c.Recipients) == 0 {
+		return fmt.Errorf("启用邮件接收时收件人地址不能为空")
+	}
+	if c.Severity == "" {
+		c.Severity = SeverityWarning
+	}
+	if !ValidSeverity(c.Severity) {
+		return fmt.Errorf("告警级别错误: %s", c.Severity)
+	}
+	if c.MaxSizeKB <= 0 || c.MaxSizeKB > 10240 {
+		return fmt.Errorf("邮件最大大小必须在1到10240KB之间")
+	}
+	return nil
+}
+
+// Accepts 判断是否接收发往该地址的邮件
+func (c MailInboundConfig) Accepts(rcpt string) bool {
+	return slices.Contains(c.Recipients, strings.ToLower(rcpt))
+}
+
+// mailWordDecoder 解码RFC 2047编码的邮件头，支持GBK等常见字符集
+var mailWordDecoder = &mime.WordDecoder{CharsetReader: charsetReader}
+
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	enc, err := htmlindex.Get(charset)
+	if err != nil {
+		return nil, fmt.Errorf("不支持的字符集: %s", charset)
+	}
+	return enc.NewDecoder().Reader(input), nil
+}
+
+// ParseMail 将收到的邮件转换为外部告警，告警来源为发件人地址，相同发件人和主题的邮件归并为同一事件
+func ParseMail(data []byte, envelopeFrom string, recipients []string, severity string) (InboundAlert, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		return InboundAlert{}, fmt.Errorf("解析邮件失败: %v", err)
+	}
+
+	// 优先使用邮件头中的发件人，解析失败时使用信封发件人
+	source := envelopeFrom
+	if from, err := mailWordDecoder.DecodeHeader(msg.Header.Get("From")); err == nil {
+		if addr, err := mail.ParseAddress(from); err == nil {
+			source = addr.Address
+		}
+	}
+	source = strings.ToLower(source)
+	if source == "" {
+		source = "mail"
+	}
+
+	subject, err := mailWordDecoder.DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		subject = msg.Header.Get("Subject")
+	}
+	subject = strings.Join(strings.Fields(subject), " ")
+	if subject == "" {
+		subject = "(无主题)"
+	}
+
+	body, err := mailText(textproto.MIMEHeader(msg.Header), msg.Body, 0)
+	if err != nil {
+		return InboundAlert{}, err
+	}
+
+	labels := map[string]string{
+		"from": source,
+		"to":   strings.Join(recipients, ","),
+	}
+	if id := msg.Header.Get("Message-Id"); id != "" {
+		labels["message_id"] = id
+	}
+	return InboundAlert{
+		Source:   source,
+		Title:    subject,
+		Message:  truncate(strings.TrimSpace(body), mailMaxMessageLen),
+		Severity: severity,
+		Labels:   labels,
+		DedupKey: subject,
+	}, nil
+}
+
+// mailText 提取邮件正文，multipart邮件优先使用text/plain部分，只有HTML时去除标签
+func mailText(header textproto.MIMEHeader, body io.Reader, depth int) (string, error) {
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		mediaType, params = "text/plain", map[string]string{}
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		if depth >= mailMaxPartDepth {
+			return "", nil
+		}
+		var plain, htmlText string
+		r := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := r.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return "", fmt.Errorf("解析邮件正文失败: %v", err)
+			}
+			// 附件不作为正文
+			if disposition, _, _ := mime.ParseMediaType(part.Header.Get("Content-Disposition")); disposition == "attachment" {
+				continue
+			}
+			text, err := mailText(part.Header, part, depth+1)
+			if err != nil {
+				return "", err
+			}
+			partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+			if partType == "text/html" {
+				if htmlText == "" {
+					htmlText = text
+				}
+			} else if plain == "" {
+				plain = text
+			}
+		}
+		if plain != "" {
+			return plain, nil
+		}
+		return htmlText, nil
+	}
+
+	if !strings.HasPrefix(mediaType, "text/") {
+		return "", nil
+	}
+
+	// multipart.Reader已经处理了quoted-printable编码
+	switch strings.ToLower(header.Get("Content-Transfer-Encoding")) {
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	}
+	// 不支持的字符集按原内容处理
+	if charset := params["charset"]; charset != "" {
+		if r, err := charsetReader(charset, body); err == nil {
+			body = r
+		}
+	}
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return "", fmt.Errorf("读取邮件正文失败: %v", err)
+	}
+	text := string(data)
+	if mediaType == "text/html" {
+		text = stripHTML(text)
+	}
+	return text, nil
+}
+
+var (
+	htmlHiddenRe = regexp.MustCompile(`(?is)<(script|style)[^>]*>.*?</(script|style)>`)
+	htmlBreakRe  = regexp.MustCompile(`(?i)<br\s*/?>|</(p|div|tr|li|h[1-6])>`)
+	htmlTagRe    = regexp.MustCompile(`<[^>]*>`)
+	blankLineRe  = regexp.MustCompile(`\n\s*\n+`)
+)
+
+// stripHTML 去除HTML标签，保留换行
+func stripHTML(s string) string {
+	s = htmlHiddenRe.ReplaceAllString(s, "")
+	s = htmlBreakRe.ReplaceAllString(s, "\n")
+	s = html.UnescapeString(htmlTagRe.ReplaceAllString(s, ""))
+	return blankLineRe.ReplaceAllString(s, "\n")
+}
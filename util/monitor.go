@@ -5,21 +5,27 @@ import (
 	"math"
 	"os"
 	"runtime"
-	"strings"
 	"time"
 
 	"github.com/shirou/gopsutil/v3/cpu"
 	"github.com/shirou/gopsutil/v3/disk"
+	"github.com/shirou/gopsutil/v3/load"
 	"github.com/shirou/gopsutil/v3/mem"
 )
 
 // SystemStatus 系统状态结构
 type SystemStatus struct {
-	CPUUsage   float64            `json:"cpu_usage"`   // CPU使用率
-	MemUsage   float64            `json:"mem_usage"`   // 内存使用率
-	DiskUsage  float64            `json:"disk_usage"`  // 磁盘使用率(平均值)
-	DiskUsages map[string]float64 `json:"disk_usages"` // 各磁盘分区使用率
-	Timestamp  int64              `json:"timestamp"`   // 时间戳
+	CPUUsage   float64             `json:"cpu_usage"`   // CPU使用率
+	MemUsage   float64             `json:"mem_usage"`   // 内存使用率
+	DiskUsage  float64             `json:"disk_usage"`  // 磁盘使用率(平均值)
+	DiskUsages map[string]float64  `json:"disk_usages"` // 各磁盘分区使用率
+	DiskStats  map[string]DiskStat `json:"disk_stats"`  // 各磁盘分区剩余空间和inode使用情况
+	Load1      float64             `json:"load1"`       // 1分钟平均负载
+	Load5      float64             `json:"load5"`       // 5分钟平均负载
+	Load15     float64             `json:"load15"`      // 15分钟平均负载
+	SwapUsage  float64             `json:"swap_usage"`  // 交换分区使用率
+	Networks   map[string]NetStat  `json:"networks"`    // 各网卡流量和错误速率
+	Timestamp  int64               `json:"timestamp"`   // 时间戳
 }
 
 // MonitorConfig 监控配置结构
@@ -29,20 +35,111 @@ type MonitorConfig struct {
 	CPUThreshold  float64 `json:"cpu_threshold"`  // CPU阈值(%)
 	MemThreshold  float64 `json:"mem_threshold"`  // 内存阈值(%)
 	DiskThreshold float64 `json:"disk_threshold"` // 磁盘阈值(%)
+	// 严重级别阈值(%)，超过时以critical级别告警，0表示不启用
+	CPUCriticalThreshold  float64 `json:"cpu_critical_threshold"`
+	MemCriticalThreshold  float64 `json:"mem_critical_threshold"`
+	DiskCriticalThreshold float64 `json:"disk_critical_threshold"`
+	// 重复通知间隔(分钟)，持续超过阈值时每隔该时间再次通知，0表示只通知一次
+	RepeatInterval int `json:"repeat_interval"`
+	// 剩余空间阈值(GiB)，分区剩余空间低于该值时告警，0表示不启用
+	DiskMinFreeGB         float64 `json:"disk_min_free_gb"`
+	DiskCriticalMinFreeGB float64 `json:"disk_critical_min_free_gb"`
+	// inode使用率阈值(%)，0表示不启用
+	InodeThreshold         float64 `json:"inode_threshold"`
+	InodeCriticalThreshold float64 `json:"inode_critical_threshold"`
+	// 1分钟平均负载阈值，0表示不启用
+	LoadThreshold         float64 `json:"load_threshold"`
+	LoadCriticalThreshold float64 `json:"load_critical_threshold"`
+	// 交换分区使用率阈值(%)，0表示不启用
+	SwapThreshold         float64 `json:"swap_threshold"`
+	SwapCriticalThreshold float64 `json:"swap_critical_threshold"`
+	// 单个网卡接收、发送速率阈值(MiB/s)和错误丢包速率阈值(个/秒)，0表示不启用
+	NetRxThreshold    float64 `json:"net_rx_threshold"`
+	NetTxThreshold    float64 `json:"net_tx_threshold"`
+	NetErrorThreshold float64 `json:"net_error_threshold"`
+	// 网卡严重级别阈值，0表示不启用
+	NetRxCriticalThreshold    float64 `json:"net_rx_critical_threshold"`
+	NetTxCriticalThreshold    float64 `json:"net_tx_critical_threshold"`
+	NetErrorCriticalThreshold float64 `json:"net_error_critical_threshold"`
+	// CPU、内存告警时附带的进程数量，按CPU和内存分别取前N个，0表示不采集
+	ProcessTopN int `json:"process_top_n"`
+	// 磁盘分区过滤规则
+	DiskFilter DiskFilter `json:"disk_filter"`
+	// 挂载点阈值规则，未匹配的分区使用DiskThreshold和DiskCriticalThreshold
+	DiskRules []DiskRule `json:"disk_rules"`
+}
+
+// DefaultMonitorConfig 默认监控配置
+func DefaultMonitorConfig() MonitorConfig {
+	return MonitorConfig{
+		Interval:       5,
+		AvgCount:       3,
+		CPUThreshold:   80.0,
+		MemThreshold:   80.0,
+		DiskThreshold:  85.0,
+		RepeatInterval: 60,
+		ProcessTopN:    5,
+		DiskFilter:     DefaultDiskFilter(),
+	}
+}
+
+// Validate 校验监控配置
+func (c MonitorConfig) Validate() error {
+	tiers := []struct {
+		name     string
+		warning  float64
+		critical float64
+		unit     string
+	}{
+		{"CPU", c.CPUThreshold, c.CPUCriticalThreshold, "%"},
+		{"内存", c.MemThreshold, c.MemCriticalThreshold, "%"},
+		{"磁盘", c.DiskThreshold, c.DiskCriticalThreshold, "%"},
+		{"inode", c.InodeThreshold, c.InodeCriticalThreshold, "%"},
+		{"负载", c.LoadThreshold, c.LoadCriticalThreshold, ""},
+		{"交换分区", c.SwapThreshold, c.SwapCriticalThreshold, "%"},
+		{"网卡接收速率", c.NetRxThreshold, c.NetRxCriticalThreshold, "MiB/s"},
+		{"网卡发送速率", c.NetTxThreshold, c.NetTxCriticalThreshold, "MiB/s"},
+		{"网卡错误丢包速率", c.NetErrorThreshold, c.NetErrorCriticalThreshold, "个/秒"},
+	}
+	for _, tier := range tiers {
+		if tier.critical > 0 && tier.critical < tier.warning {
+			return fmt.Errorf("%s严重阈值%.2f%s不能低于告警阈值%.2f%s", tier.name, tier.critical, tier.unit, tier.warning, tier.unit)
+		}
+	}
+	if err := validateMinFree("磁盘", c.DiskMinFreeGB, c.DiskCriticalMinFreeGB); err != nil {
+		return err
+	}
+	if err := c.DiskFilter.Validate(); err != nil {
+		return err
+	}
+	for _, rule := range c.DiskRules {
+		if err := rule.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // SystemMonitor 系统监控器结构
 type SystemMonitor struct {
 	Config        MonitorConfig
 	StatusHistory []SystemStatus
-	AlertFunc     func(string) error // 告警函数
+	AlertFunc     func(Alert) error // 告警函数
+	Tracker       *AlertTracker     // 各告警条件的状态
 }
 
-// GetSystemStatus 获取当前系统状态
-func GetSystemStatus() (*SystemStatus, error) {
+// GetSystemStatus 获取当前系统状态，只统计符合过滤规则的磁盘分区
+// 网卡速率按netCounter上次采集以来计算，netCounter为nil时按采集CPU使用率的1秒计算
+func GetSystemStatus(filter DiskFilter, netCounter *NetCounter) (*SystemStatus, error) {
 	status := &SystemStatus{
 		Timestamp:  time.Now().Unix(),
 		DiskUsages: make(map[string]float64),
+		DiskStats:  make(map[string]DiskStat),
+		Networks:   make(map[string]NetStat),
+	}
+	if netCounter == nil {
+		netCounter = &NetCounter{}
+		netCounter.Stats()
 	}
 
 	// 获取CPU使用率
@@ -59,6 +156,17 @@ func GetSystemStatus() (*SystemStatus, error) {
 	}
 	status.MemUsage = memStat.UsedPercent
 
+	// 获取平均负载、交换分区和网卡速率，部分平台不支持时忽略
+	if avg, err := load.Avg(); err == nil {
+		status.Load1, status.Load5, status.Load15 = avg.Load1, avg.Load5, avg.Load15
+	}
+	if swapStat, err := mem.SwapMemory(); err == nil {
+		status.SwapUsage = swapStat.UsedPercent
+	}
+	if networks, err := netCounter.Stats(); err == nil {
+		status.Networks = networks
+	}
+
 	// 获取所有磁盘分区使用率
 	parts, err := disk.Partitions(true)
 	if err != nil {
@@ -74,21 +182,19 @@ func GetSystemStatus() (*SystemStatus, error) {
 		}
 		status.DiskUsage = diskStat.UsedPercent
 		status.DiskUsages["/"] = diskStat.UsedPercent
+		status.DiskStats["/"] = NewDiskStat(diskStat)
 	} else {
 		var totalUsage float64
 		var validPartitions int
 
 		// 遍历所有分区
 		for _, part := range parts {
-			// 跳过一些虚拟文件系统
-			if strings.HasPrefix(part.Fstype, "tmpfs") ||
-				strings.HasPrefix(part.Fstype, "sysfs") ||
-				strings.HasPrefix(part.Fstype, "proc") ||
-				strings.HasPrefix(part.Fstype, "devtmpfs") ||
-				strings.HasPrefix(part.Fstype, "cgroup") ||
-				part.Mountpoint == "/dev" ||
-				part.Mountpoint == "/sys" ||
-				part.Mountpoint == "/proc" {
+			// 跳过不需要监控的分区
+			if !filter.Match(part.Mountpoint, part.Fstype) {
+				continue
+			}
+			// 同一挂载点只统计一次
+			if _, exists := status.DiskUsages[part.Mountpoint]; exists {
 				continue
 			}
 
@@ -99,6 +205,7 @@ func GetSystemStatus() (*SystemStatus, error) {
 			}
 
 			status.DiskUsages[part.Mountpoint] = diskStat.UsedPercent
+			status.DiskStats[part.Mountpoint] = NewDiskStat(diskStat)
 			totalUsage += diskStat.UsedPercent
 			validPartitions++
 		}
@@ -116,11 +223,12 @@ func GetSystemStatus() (*SystemStatus, error) {
 }
 
 // NewSystemMonitor 创建系统监控器
-func NewSystemMonitor(config MonitorConfig, alertFunc func(string) error) *SystemMonitor {
+func NewSystemMonitor(config MonitorConfig, alertFunc func(Alert) error) *SystemMonitor {
 	return &SystemMonitor{
 		Config:        config,
 		StatusHistory: make([]SystemStatus, 0),
 		AlertFunc:     alertFunc,
+		Tracker:       NewAlertTracker(time.Duration(config.RepeatInterval) * time.Minute),
 	}
 }
 
@@ -135,61 +243,123 @@ func (m *SystemMonitor) AddStatus(status SystemStatus) {
 }
 
 // CheckThreshold 检查阈值并触发告警
+// CPU、内存和各磁盘分区分别作为独立的告警条件，持续超过阈值时按重复通知间隔发送告警，恢复正常时发送恢复通知
 func (m *SystemMonitor) CheckThreshold() error {
 	// 确保有足够的历史记录
 	if len(m.StatusHistory) < m.Config.AvgCount {
 		return nil
 	}
 
-	// 计算平均值
-	var cpuSum, memSum, diskSum float64
+	return m.notify(m.evaluate(), time.Now())
+}
+
+// evaluate 计算平均值并检查各告警条件
+func (m *SystemMonitor) evaluate() []thresholdCheck {
+	var cpuSum, memSum, loadSum, swapSum float64
+	disks := make(map[string]*diskSum)
+	networks := make(map[string]*netSum)
 	for _, status := range m.StatusHistory {
 		cpuSum += status.CPUUsage
 		memSum += status.MemUsage
-		diskSum += status.DiskUsage
+		loadSum += status.Load1
+		swapSum += status.SwapUsage
+		for mountPoint, stat := range status.DiskStats {
+			sum, exists := disks[mountPoint]
+			if !exists {
+				sum = &diskSum{}
+				disks[mountPoint] = sum
+			}
+			sum.add(stat)
+		}
+		for name, stat := range status.Networks {
+			sum, exists := networks[name]
+			if !exists {
+				sum = &netSum{}
+				networks[name] = sum
+			}
+			sum.add(stat)
+		}
 	}
 
-	avgCPU := cpuSum / float64(len(m.StatusHistory))
-	avgMem := memSum / float64(len(m.StatusHistory))
-	avgDisk := diskSum / float64(len(m.StatusHistory))
+	count := float64(len(m.StatusHistory))
+	avgCPU := cpuSum / count
+	avgMem := memSum / count
 
-	// 检查是否超过阈值
-	alerts := make([]string, 0)
-	if avgCPU > m.Config.CPUThreshold {
-		alerts = append(alerts, fmt.Sprintf("CPU使用率%.2f%%超过阈值%.2f%%", avgCPU, m.Config.CPUThreshold))
+	checks := make([]thresholdCheck, 0)
+	checks = append(checks, percentCheck("cpu", "CPU使用率", avgCPU, m.Config.CPUThreshold, m.Config.CPUCriticalThreshold))
+	checks = append(checks, percentCheck("mem", "内存使用率", avgMem, m.Config.MemThreshold, m.Config.MemCriticalThreshold))
+	if m.Config.LoadThreshold > 0 || m.Config.LoadCriticalThreshold > 0 {
+		checks = append(checks, valueCheck("load", "1分钟平均负载", loadSum/count, m.Config.LoadThreshold, m.Config.LoadCriticalThreshold, ""))
+	}
+	if m.Config.SwapThreshold > 0 || m.Config.SwapCriticalThreshold > 0 {
+		checks = append(checks, percentCheck("swap", "交换分区使用率", swapSum/count, m.Config.SwapThreshold, m.Config.SwapCriticalThreshold))
 	}
 
-	if avgMem > m.Config.MemThreshold {
-		alerts = append(alerts, fmt.Sprintf("内存使用率%.2f%%超过阈值%.2f%%", avgMem, m.Config.MemThreshold))
+	// 各磁盘分区按各自的阈值规则单独检查
+	for mountPoint, sum := range disks {
+		checks = append(checks, sum.checks(mountPoint, m.Config.DiskRuleFor(mountPoint))...)
 	}
 
-	if avgDisk > m.Config.DiskThreshold {
-		// 检查具体的磁盘分区使用情况
-		latestStatus := m.StatusHistory[len(m.StatusHistory)-1]
-		diskAlerts := make([]string, 0)
-		for mountPoint, usage := range latestStatus.DiskUsages {
-			if usage > m.Config.DiskThreshold {
-				diskAlerts = append(diskAlerts, fmt.Sprintf("%s分区使用率%.2f%%", mountPoint, usage))
-			}
-		}
+	// 各网卡单独检查
+	for name, sum := range networks {
+		checks = append(checks, sum.checks(name, m.Config)...)
+	}
 
-		if len(diskAlerts) > 0 {
-			alerts = append(alerts, fmt.Sprintf("磁盘使用率超过阈值%.2f%%: %s", m.Config.DiskThreshold, strings.Join(diskAlerts, ", ")))
-		} else {
-			alerts = append(alerts, fmt.Sprintf("平均磁盘使用率%.2f%%超过阈值%.2f%%", avgDisk, m.Config.DiskThreshold))
-		}
+	return checks
+}
+
+// percentCheck 检查百分比类型的告警条件，critical大于0且超过时为严重级别，阈值为0时不启用
+func percentCheck(key, name string, value, warning, critical float64) thresholdCheck {
+	return valueCheck(key, name, value, warning, critical, "%")
+}
+
+// valueCheck 检查数值类型的告警条件，超过阈值时告警，critical大于0且超过时为严重级别，阈值为0时不启用
+func valueCheck(key, name string, value, warning, critical float64, unit string) thresholdCheck {
+	check := thresholdCheck{Key: key, Name: name, Value: fmt.Sprintf("%.2f%s", value, unit)}
+	if critical > 0 && value > critical {
+		check.Severity = SeverityCritical
+		check.Breach = fmt.Sprintf("%s%.2f%s超过严重阈值%.2f%s", name, value, unit, critical, unit)
+	} else if warning > 0 && value > warning {
+		check.Severity = SeverityWarning
+		check.Breach = fmt.Sprintf("%s%.2f%s超过阈值%.2f%s", name, value, unit, warning, unit)
 	}
+	return check
+}
 
-	// 如果有超过阈值的情况，触发告警
-	if len(alerts) > 0 {
-		alertMsg := "系统监控告警:\n" + fmt.Sprintf("时间: %s\n", time.Now().Format("2006-01-02 15:04:05"))
-		for _, alert := range alerts {
-			alertMsg += alert + "\n"
-		}
-		return m.AlertFunc(alertMsg)
+// freeCheck 检查剩余空间类型的告警条件，低于阈值时告警，阈值为0时不启用
+func freeCheck(key, name string, freeBytes uint64, warningGB, criticalGB float64) thresholdCheck {
+	free := float64(freeBytes) / GiB
+	check := thresholdCheck{Key: key, Name: name, Value: fmt.Sprintf("%.2fGiB", free)}
+	if criticalGB > 0 && free < criticalGB {
+		check.Severity = SeverityCritical
+		check.Breach = fmt.Sprintf("%s%.2fGiB低于严重阈值%.2fGiB", name, free, criticalGB)
+	} else if warningGB > 0 && free < warningGB {
+		check.Severity = SeverityWarning
+		check.Breach = fmt.Sprintf("%s%.2fGiB低于阈值%.2fGiB", name, free, warningGB)
 	}
+	return check
+}
 
-	return nil
+// notify 根据检查结果更新告警状态，发送告警和恢复通知
+func (m *SystemMonitor) notify(checks []thresholdCheck, now time.Time) error {
+	n := CheckNotifier{Source: AlertSourceMonitor, Title: "系统监控告警", Tracker: m.Tracker, AlertFunc: m.AlertFunc}
+
+	// CPU和内存告警附带进程快照，同一次检查只采集一次
+	var snapshot *ProcessSnapshot
+	return n.Notify(checks, now, func(check thresholdCheck, alert *Alert) error {
+		if (check.Key != "cpu" && check.Key != "mem") || m.Config.ProcessTopN <= 0 {
+			return nil
+		}
+		if snapshot == nil {
+			var err error
+			if snapshot, err = GetProcessSnapshot(m.Config.ProcessTopN); err != nil {
+				return err
+			}
+		}
+		alert.Processes = snapshot
+		alert.Message += "\n" + snapshot.String()
+		return nil
+	})
 }
 
 // GetCPUCount 获取CPU核心数
@@ -0,0 +1,191 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// Nagios插件的返回值
+const (
+	NagiosOK       = 0
+	NagiosWarning  = 1
+	NagiosCritical = 2
+	NagiosUnknown  = 3
+)
+
+var nagiosStatusNames = []string{"OK", "WARNING", "CRITICAL", "UNKNOWN"}
+
+// NagiosStatusName 返回值对应的状态名称
+func NagiosStatusName(code int) string {
+	if code < NagiosOK || code > NagiosUnknown {
+		code = NagiosUnknown
+	}
+	return nagiosStatusNames[code]
+}
+
+// nagiosSeverity 返回值对应的告警级别
+func nagiosSeverity(code int) string {
+	if code == NagiosCritical {
+		return SeverityCritical
+	}
+	return SeverityWarning
+}
+
+// PerfData Nagios插件输出的性能数据，格式为 'label'=value[UOM];[warn];[crit];[min];[max]
+type PerfData struct {
+	Label string   `json:"label"`
+	Value float64  `json:"value"`
+	UOM   string   `json:"uom,omitempty"`  // 单位，如s、%、B、KB、c
+	Warn  string   `json:"warn,omitempty"` // 告警阈值范围
+	Crit  string   `json:"crit,omitempty"` // 严重阈值范围
+	Min   *float64 `json:"min,omitempty"`
+	Max   *float64 `json:"max,omitempty"`
+}
+
+// runNagiosPlugin 执行Nagios插件，退出码作为返回值，大于3的退出码视为UNKNOWN
+// 插件无法执行或超时时返回UNKNOWN，输出为错误原因
+func runNagiosPlugin(cmd *exec.Cmd) (int, string, error) {
+	output, err := cmd.Output()
+	if err == nil {
+		return NagiosOK, string(output), nil
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() >= 0 {
+		code := exitErr.ExitCode()
+		if code > NagiosUnknown {
+			code = NagiosUnknown
+		}
+		return code, string(output), nil
+	}
+
+	err = fmt.Errorf("执行插件失败: %v", err)
+	if len(output) == 0 {
+		output = []byte(err.Error())
+	}
+	return NagiosUnknown, string(output), err
+}
+
+// ParseNagiosOutput 解析Nagios插件输出，返回第一行的状态文本和性能数据
+// 性能数据位于第一行|之后，以及后续行中第一个|之后的所有内容
+func ParseNagiosOutput(output string) (string, []PerfData) {
+	lines := strings.Split(strings.TrimRight(output, "\r\n"), "\n")
+
+	status, perf, _ := strings.Cut(lines[0], "|")
+	perfParts := []string{perf}
+	inPerf := false
+	for _, line := range lines[1:] {
+		if inPerf {
+			perfParts = append(perfParts, line)
+			continue
+		}
+		if _, after, found := strings.Cut(line, "|"); found {
+			inPerf = true
+			perfParts = append(perfParts, after)
+		}
+	}
+
+	return strings.TrimSpace(status), ParsePerfData(strings.Join(perfParts, " "))
+}
+
+// ParsePerfData 解析性能数据，忽略格式错误和值为U(无法获取)的指标
+func ParsePerfData(s string) []PerfData {
+	var metrics []PerfData
+	for {
+		s = strings.TrimLeft(s, " \t\r\n")
+		if s == "" {
+			return metrics
+		}
+
+		// 标签可以用单引号包含空格和=，两个单引号表示一个单引号
+		var label string
+		if s[0] == '\'' {
+			var b strings.Builder
+			i := 1
+			for i < len(s) {
+				if s[i] == '\'' {
+					if i+1 < len(s) && s[i+1] == '\'' {
+						b.WriteByte('\'')
+						i += 2
+						continue
+					}
+					break
+				}
+				b.WriteByte(s[i])
+				i++
+			}
+			label, s = b.String(), s[min(i+1, len(s)):]
+			if !strings.HasPrefix(s, "=") {
+				s = skipPerfToken(s)
+				continue
+			}
+			s = s[1:]
+		} else {
+			eq := strings.IndexByte(s, '=')
+			space := strings.IndexAny(s, " \t\r\n")
+			if eq <= 0 || (space >= 0 && space < eq) {
+				s = skipPerfToken(s)
+				continue
+			}
+			label, s = s[:eq], s[eq+1:]
+		}
+
+		end := strings.IndexAny(s, " \t\r\n")
+		if end < 0 {
+			end = len(s)
+		}
+		if metric, ok := parsePerfValue(label, s[:end]); ok {
+			metrics = append(metrics, metric)
+		}
+		s = s[end:]
+	}
+}
+
+func skipPerfToken(s string) string {
+	if end := strings.IndexAny(s, " \t\r\n"); end >= 0 {
+		return s[end:]
+	}
+	return ""
+}
+
+// parsePerfValue 解析 value[UOM];[warn];[crit];[min];[max] 部分
+func parsePerfValue(label, s string) (PerfData, bool) {
+	fields := strings.Split(s, ";")
+	value := fields[0]
+	numEnd := strings.IndexFunc(value, func(r rune) bool {
+		return !(r >= '0' && r <= '9' || r == '.' || r == '-' || r == '+' || r == 'e' || r == 'E')
+	})
+	if numEnd < 0 {
+		numEnd = len(value)
+	}
+	v, err := strconv.ParseFloat(value[:numEnd], 64)
+	if label == "" || err != nil {
+		return PerfData{}, false
+	}
+
+	metric := PerfData{Label: label, Value: v, UOM: value[numEnd:]}
+	if len(fields) > 1 {
+		metric.Warn = fields[1]
+	}
+	if len(fields) > 2 {
+		metric.Crit = fields[2]
+	}
+	if len(fields) > 3 {
+		metric.Min = parsePerfFloat(fields[3])
+	}
+	if len(fields) > 4 {
+		metric.Max = parsePerfFloat(fields[4])
+	}
+	return metric, true
+}
+
+func parsePerfFloat(s string) *float64 {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil
+	}
+	return &v
+}
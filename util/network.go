@@ -0,0 +1,121 @@
+package util
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/net"
+)
+
+// MiB 1MiB对应的字节数
+const MiB = 1 << 20
+
+// NetStat 网卡流量和错误速率
+type NetStat struct {
+	RxBytesPerSec  float64 `json:"rx_bytes_per_sec"`  // 接收速率(字节/秒)
+	TxBytesPerSec  float64 `json:"tx_bytes_per_sec"`  // 发送速率(字节/秒)
+	RxErrorsPerSec float64 `json:"rx_errors_per_sec"` // 接收错误(个/秒)
+	TxErrorsPerSec float64 `json:"tx_errors_per_sec"` // 发送错误(个/秒)
+	RxDropsPerSec  float64 `json:"rx_drops_per_sec"`  // 接收丢包(个/秒)
+	TxDropsPerSec  float64 `json:"tx_drops_per_sec"`  // 发送丢包(个/秒)
+}
+
+// ErrorsPerSec 错误和丢包的总速率
+func (s NetStat) ErrorsPerSec() float64 {
+	return s.RxErrorsPerSec + s.TxErrorsPerSec + s.RxDropsPerSec + s.TxDropsPerSec
+}
+
+// NetCounter 记录上一次采集的网卡计数器，用于计算速率，定时采集和实时查询应使用各自的计数器
+type NetCounter struct {
+	mu       sync.Mutex
+	counters map[string]net.IOCountersStat
+	time     time.Time
+}
+
+// Stats 获取各网卡自上次采集以来的速率，首次采集时返回空结果
+func (c *NetCounter) Stats() (map[string]NetStat, error) {
+	counters, err := net.IOCounters(true)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := make(map[string]NetStat)
+	elapsed := now.Sub(c.time).Seconds()
+	current := make(map[string]net.IOCountersStat, len(counters))
+	for _, counter := range counters {
+		current[counter.Name] = counter
+
+		// 跳过回环网卡
+		if counter.Name == "lo" {
+			continue
+		}
+		last, exists := c.counters[counter.Name]
+		if !exists || elapsed <= 0 {
+			continue
+		}
+		// 计数器被重置(如网卡重新加载)时本次不计算
+		if counter.BytesRecv < last.BytesRecv || counter.BytesSent < last.BytesSent {
+			continue
+		}
+
+		stats[counter.Name] = NetStat{
+			RxBytesPerSec:  counterRate(counter.BytesRecv, last.BytesRecv, elapsed),
+			TxBytesPerSec:  counterRate(counter.BytesSent, last.BytesSent, elapsed),
+			RxErrorsPerSec: counterRate(counter.Errin, last.Errin, elapsed),
+			TxErrorsPerSec: counterRate(counter.Errout, last.Errout, elapsed),
+			RxDropsPerSec:  counterRate(counter.Dropin, last.Dropin, elapsed),
+			TxDropsPerSec:  counterRate(counter.Dropout, last.Dropout, elapsed),
+		}
+	}
+
+	c.counters = current
+	c.time = now
+	return stats, nil
+}
+
+// counterRate 计算计数器的每秒增量
+func counterRate(current, last uint64, elapsed float64) float64 {
+	if current < last {
+		return 0
+	}
+	return float64(current-last) / elapsed
+}
+
+// netSum 网卡多次采样的累计值，用于计算平均值
+type netSum struct {
+	rx     float64
+	tx     float64
+	errors float64
+	count  int
+}
+
+func (s *netSum) add(stat NetStat) {
+	s.rx += stat.RxBytesPerSec
+	s.tx += stat.TxBytesPerSec
+	s.errors += stat.ErrorsPerSec()
+	s.count++
+}
+
+// checks 检查网卡的接收、发送速率和错误丢包速率
+func (s *netSum) checks(name string, config MonitorConfig) []thresholdCheck {
+	count := float64(s.count)
+	checks := make([]thresholdCheck, 0, 3)
+	if config.NetRxThreshold > 0 || config.NetRxCriticalThreshold > 0 {
+		checks = append(checks, valueCheck("net_rx:"+name, fmt.Sprintf("网卡%s接收速率", name),
+			s.rx/count/MiB, config.NetRxThreshold, config.NetRxCriticalThreshold, "MiB/s"))
+	}
+	if config.NetTxThreshold > 0 || config.NetTxCriticalThreshold > 0 {
+		checks = append(checks, valueCheck("net_tx:"+name, fmt.Sprintf("网卡%s发送速率", name),
+			s.tx/count/MiB, config.NetTxThreshold, config.NetTxCriticalThreshold, "MiB/s"))
+	}
+	if config.NetErrorThreshold > 0 || config.NetErrorCriticalThreshold > 0 {
+		checks = append(checks, valueCheck("net_error:"+name, fmt.Sprintf("网卡%s错误丢包速率", name),
+			s.errors/count, config.NetErrorThreshold, config.NetErrorCriticalThreshold, "个/秒"))
+	}
+	return checks
+}
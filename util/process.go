@@ -0,0 +1,121 @@
+package util
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// ProcessInfo 进程信息
+type ProcessInfo struct {
+	PID        int32   `json:"pid"`
+	Name       string  `json:"name"`
+	User       string  `json:"user"`
+	Cmdline    string  `json:"cmdline"`
+	CPUPercent float64 `json:"cpu_percent"` // 采样期间的CPU使用率
+	MemPercent float32 `json:"mem_percent"` // 内存使用率
+	RSS        uint64  `json:"rss"`         // 常驻内存(字节)
+}
+
+// ProcessSnapshot 进程快照，分别按CPU和内存排序的前N个进程
+type ProcessSnapshot struct {
+	TopCPU    []ProcessInfo `json:"top_cpu"`
+	TopMem    []ProcessInfo `json:"top_mem"`
+	Timestamp int64         `json:"timestamp"`
+}
+
+// processSampleInterval 计算进程CPU使用率的采样间隔
+const processSampleInterval = time.Second
+
+// GetProcessSnapshot 获取CPU和内存占用最高的前n个进程
+func GetProcessSnapshot(n int) (*ProcessSnapshot, error) {
+	procs, err := process.Processes()
+	if err != nil {
+		return nil, fmt.Errorf("获取进程列表失败: %v", err)
+	}
+
+	cpuPercents := sampleCPUPercent(procs)
+
+	infos := make([]ProcessInfo, 0, len(procs))
+	for _, p := range procs {
+		// 采样期间已退出的进程忽略
+		info, err := newProcessInfo(p)
+		if err != nil {
+			continue
+		}
+		info.CPUPercent = cpuPercents[p.Pid]
+		infos = append(infos, info)
+	}
+
+	snapshot := &ProcessSnapshot{Timestamp: time.Now().Unix()}
+
+	sort.Slice(infos, func(i, j int) bool { return infos[i].CPUPercent > infos[j].CPUPercent })
+	snapshot.TopCPU = append(snapshot.TopCPU, infos[:min(n, len(infos))]...)
+
+	sort.Slice(infos, func(i, j int) bool { return infos[i].RSS > infos[j].RSS })
+	snapshot.TopMem = append(snapshot.TopMem, infos[:min(n, len(infos))]...)
+
+	return snapshot, nil
+}
+
+// newProcessInfo 获取进程信息，不包括CPU使用率
+func newProcessInfo(p *process.Process) (ProcessInfo, error) {
+	memInfo, err := p.MemoryInfo()
+	if err != nil {
+		return ProcessInfo{}, err
+	}
+	info := ProcessInfo{PID: p.Pid, RSS: memInfo.RSS}
+	info.Name, _ = p.Name()
+	info.User, _ = p.Username()
+	info.MemPercent, _ = p.MemoryPercent()
+	if cmdline, err := p.Cmdline(); err == nil {
+		// 合并命令行中的换行和连续空白，避免破坏告警内容格式
+		info.Cmdline = truncate(strings.Join(strings.Fields(cmdline), " "), 200)
+	}
+	return info, nil
+}
+
+// sampleCPUPercent 两次采样进程CPU时间，计算采样期间各进程的CPU使用率
+func sampleCPUPercent(procs []*process.Process) map[int32]float64 {
+	cpuTimes := make(map[int32]float64, len(procs))
+	for _, p := range procs {
+		if times, err := p.Times(); err == nil {
+			cpuTimes[p.Pid] = times.User + times.System
+		}
+	}
+	start := time.Now()
+	time.Sleep(processSampleInterval)
+	elapsed := time.Since(start).Seconds()
+
+	percents := make(map[int32]float64, len(cpuTimes))
+	for _, p := range procs {
+		last, exists := cpuTimes[p.Pid]
+		if !exists {
+			continue
+		}
+		if times, err := p.Times(); err == nil {
+			percents[p.Pid] = (times.User + times.System - last) / elapsed * 100
+		}
+	}
+	return percents
+}
+
+// String 格式化进程快照，用于告警内容
+func (s *ProcessSnapshot) String() string {
+	var b strings.Builder
+	b.WriteString("CPU占用最高的进程:\n")
+	writeProcesses(&b, s.TopCPU)
+	b.WriteString("内存占用最高的进程:\n")
+	writeProcesses(&b, s.TopMem)
+	return b.String()
+}
+
+func writeProcesses(b *strings.Builder, infos []ProcessInfo) {
+	for _, info := range infos {
+		fmt.Fprintf(b, "  PID %d %s 用户:%s CPU:%.1f%% 内存:%.1f%%(%.1fMiB) %s\n",
+			info.PID, info.Name, info.User, info.CPUPercent, info.MemPercent, float64(info.RSS)/MiB, info.Cmdline)
+	}
+}
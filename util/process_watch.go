@@ -0,0 +1,273 @@
+package util
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// 进程匹配方式
+const (
+	ProcessMatchName    = "name"    // 按进程名匹配，支持通配符
+	ProcessMatchCmdline = "cmdline" // 按命令行正则匹配
+	ProcessMatchPidfile = "pidfile" // 按pid文件匹配
+)
+
+// AlertSourceProcess 进程监控告警来源
+const AlertSourceProcess = "process"
+
+// ProcessWatcher 进程监控配置
+type ProcessWatcher struct {
+	ID                 int     `json:"id"`
+	Name               string  `json:"name"`                 // 监控名称
+	MatchType          string  `json:"match_type"`           // 匹配方式 name/cmdline/pidfile
+	Pattern            string  `json:"pattern"`              // 进程名、命令行正则或pid文件路径
+	MinCount           int     `json:"min_count"`            // 最少实例数
+	MaxCount           int     `json:"max_count"`            // 最多实例数，0表示不限制
+	Severity           string  `json:"severity"`             // 实例数不符合时的告警级别，默认critical
+	CPUThreshold       float64 `json:"cpu_threshold"`        // 单个进程CPU使用率阈值(%)，0表示不启用
+	RSSThresholdMB     float64 `json:"rss_threshold_mb"`     // 单个进程常驻内存阈值(MiB)，0表示不启用
+	OpenFilesThreshold int     `json:"open_files_threshold"` // 单个进程打开文件数阈值，0表示不启用
+	Enabled            bool    `json:"enabled"`
+	CreatedAt          string  `json:"created_at"`
+}
+
+// Validate 校验进程监控配置
+func (w ProcessWatcher) Validate() error {
+	if w.Name == "" {
+		return fmt.Errorf("监控名称不能为空")
+	}
+	if w.Pattern == "" {
+		return fmt.Errorf("匹配规则不能为空")
+	}
+	switch w.MatchType {
+	case ProcessMatchName:
+		if _, err := path.Match(w.Pattern, ""); err != nil {
+			return fmt.Errorf("进程名%s格式错误: %v", w.Pattern, err)
+		}
+	case ProcessMatchCmdline:
+		if _, err := regexp.Compile(w.Pattern); err != nil {
+			return fmt.Errorf("命令行正则%s格式错误: %v", w.Pattern, err)
+		}
+	case ProcessMatchPidfile:
+	default:
+		return fmt.Errorf("不支持的匹配方式: %s", w.MatchType)
+	}
+	if w.MinCount < 0 || w.MaxCount < 0 {
+		return fmt.Errorf("实例数不能为负数")
+	}
+	if w.MaxCount > 0 && w.MaxCount < w.MinCount {
+		return fmt.Errorf("最多实例数%d不能小于最少实例数%d", w.MaxCount, w.MinCount)
+	}
+	if w.Severity != "" && !ValidSeverity(w.Severity) {
+		return fmt.Errorf("告警级别错误: %s", w.Severity)
+	}
+	return nil
+}
+
+// FindProcesses 查找符合监控配置的进程，用于测试匹配规则
+func FindProcesses(w ProcessWatcher) ([]ProcessInfo, error) {
+	var procs []*process.Process
+	if w.MatchType == ProcessMatchPidfile {
+		var err error
+		if procs, err = findPidfileProcess(w.Pattern); err != nil {
+			return nil, err
+		}
+	} else {
+		all, err := process.Processes()
+		if err != nil {
+			return nil, fmt.Errorf("获取进程列表失败: %v", err)
+		}
+		procs = matchProcesses([]ProcessWatcher{w}, all)[w.Name]
+	}
+
+	infos := make([]ProcessInfo, 0, len(procs))
+	for _, p := range procs {
+		if info, err := newProcessInfo(p); err == nil {
+			infos = append(infos, info)
+		}
+	}
+	return infos, nil
+}
+
+// findPidfileProcess 根据pid文件查找进程，文件不存在或进程已退出时返回空列表
+func findPidfileProcess(pidfile string) ([]*process.Process, error) {
+	data, err := os.ReadFile(pidfile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("读取pid文件失败: %v", err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("pid文件%s内容错误: %v", pidfile, err)
+	}
+	if exists, _ := process.PidExists(int32(pid)); !exists {
+		return nil, nil
+	}
+	p, err := process.NewProcess(int32(pid))
+	if err != nil {
+		return nil, nil
+	}
+	return []*process.Process{p}, nil
+}
+
+// matchProcesses 按进程名和命令行匹配各监控配置对应的进程
+func matchProcesses(watchers []ProcessWatcher, procs []*process.Process) map[string][]*process.Process {
+	result := make(map[string][]*process.Process, len(watchers))
+	for _, w := range watchers {
+		var re *regexp.Regexp
+		if w.MatchType == ProcessMatchCmdline {
+			var err error
+			if re, err = regexp.Compile(w.Pattern); err != nil {
+				continue
+			}
+		}
+		for _, p := range procs {
+			var matched bool
+			switch w.MatchType {
+			case ProcessMatchName:
+				name, err := p.Name()
+				if err == nil {
+					matched, _ = path.Match(w.Pattern, name)
+				}
+			case ProcessMatchCmdline:
+				cmdline, err := p.Cmdline()
+				matched = err == nil && re.MatchString(cmdline)
+			}
+			if matched {
+				result[w.Name] = append(result[w.Name], p)
+			}
+		}
+	}
+	return result
+}
+
+// ProcessMonitor 进程监控器
+type ProcessMonitor struct {
+	AlertFunc func(Alert) error // 告警函数
+	Tracker   *AlertTracker     // 各告警条件的状态
+}
+
+// NewProcessMonitor 创建进程监控器
+func NewProcessMonitor(tracker *AlertTracker, alertFunc func(Alert) error) *ProcessMonitor {
+	return &ProcessMonitor{
+		AlertFunc: alertFunc,
+		Tracker:   tracker,
+	}
+}
+
+// Check 检查各进程监控配置，实例数不符合或进程资源超过阈值时告警
+func (m *ProcessMonitor) Check(watchers []ProcessWatcher) error {
+	if len(watchers) == 0 && len(m.Tracker.Firing()) == 0 {
+		return nil
+	}
+
+	procs, err := process.Processes()
+	if err != nil {
+		return fmt.Errorf("获取进程列表失败: %v", err)
+	}
+	matched := matchProcesses(watchers, procs)
+
+	errs := make([]string, 0)
+	for _, w := range watchers {
+		if w.MatchType != ProcessMatchPidfile {
+			continue
+		}
+		if matched[w.Name], err = findPidfileProcess(w.Pattern); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	// 只对配置了CPU阈值的进程采样
+	sampled := make([]*process.Process, 0)
+	for _, w := range watchers {
+		if w.CPUThreshold > 0 {
+			sampled = append(sampled, matched[w.Name]...)
+		}
+	}
+	cpuPercents := make(map[int32]float64)
+	if len(sampled) > 0 {
+		cpuPercents = sampleCPUPercent(sampled)
+	}
+
+	checks := make([]thresholdCheck, 0, len(watchers))
+	for _, w := range watchers {
+		checks = append(checks, watcherChecks(w, matched[w.Name], cpuPercents)...)
+	}
+
+	n := CheckNotifier{Source: AlertSourceProcess, Title: "进程监控告警", Tracker: m.Tracker, AlertFunc: m.AlertFunc}
+	if err := n.Notify(checks, time.Now(), nil); err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// watcherChecks 检查单个进程监控配置的实例数和资源使用
+func watcherChecks(w ProcessWatcher, procs []*process.Process, cpuPercents map[int32]float64) []thresholdCheck {
+	severity := w.Severity
+	if severity == "" {
+		severity = SeverityCritical
+	}
+
+	count := len(procs)
+	countCheck := thresholdCheck{Key: "count:" + w.Name, Name: "进程" + w.Name, Value: fmt.Sprintf("%d个实例", count)}
+	if count < w.MinCount {
+		countCheck.Severity = severity
+		countCheck.Breach = fmt.Sprintf("进程%s实例数%d少于%d(%s: %s)", w.Name, count, w.MinCount, w.MatchType, w.Pattern)
+	} else if w.MaxCount > 0 && count > w.MaxCount {
+		countCheck.Severity = severity
+		countCheck.Breach = fmt.Sprintf("进程%s实例数%d超过%d(%s: %s)", w.Name, count, w.MaxCount, w.MatchType, w.Pattern)
+	}
+	checks := []thresholdCheck{countCheck}
+
+	// 资源检查记录超过阈值的进程
+	var cpuBreaches, rssBreaches, fileBreaches []string
+	for _, p := range procs {
+		if w.CPUThreshold > 0 && cpuPercents[p.Pid] > w.CPUThreshold {
+			cpuBreaches = append(cpuBreaches, fmt.Sprintf("PID %d %.2f%%", p.Pid, cpuPercents[p.Pid]))
+		}
+		if w.RSSThresholdMB > 0 {
+			if memInfo, err := p.MemoryInfo(); err == nil && float64(memInfo.RSS)/MiB > w.RSSThresholdMB {
+				rssBreaches = append(rssBreaches, fmt.Sprintf("PID %d %.2fMiB", p.Pid, float64(memInfo.RSS)/MiB))
+			}
+		}
+		if w.OpenFilesThreshold > 0 {
+			if fds, err := p.NumFDs(); err == nil && int(fds) > w.OpenFilesThreshold {
+				fileBreaches = append(fileBreaches, fmt.Sprintf("PID %d %d个", p.Pid, fds))
+			}
+		}
+	}
+	if w.CPUThreshold > 0 {
+		checks = append(checks, resourceCheck("cpu:"+w.Name, "进程"+w.Name+"CPU使用率", fmt.Sprintf("超过%.2f%%", w.CPUThreshold), cpuBreaches))
+	}
+	if w.RSSThresholdMB > 0 {
+		checks = append(checks, resourceCheck("rss:"+w.Name, "进程"+w.Name+"常驻内存", fmt.Sprintf("超过%.2fMiB", w.RSSThresholdMB), rssBreaches))
+	}
+	if w.OpenFilesThreshold > 0 {
+		checks = append(checks, resourceCheck("open_files:"+w.Name, "进程"+w.Name+"打开文件数", fmt.Sprintf("超过%d", w.OpenFilesThreshold), fileBreaches))
+	}
+	return checks
+}
+
+// resourceCheck 根据超过阈值的进程列表生成检查结果
+func resourceCheck(key, name, threshold string, breaches []string) thresholdCheck {
+	check := thresholdCheck{Key: key, Name: name, Value: "无进程超过阈值"}
+	if len(breaches) > 0 {
+		check.Value = strings.Join(breaches, ", ")
+		check.Severity = SeverityWarning
+		check.Breach = fmt.Sprintf("%s%s: %s", name, threshold, check.Value)
+	}
+	return check
+}
@@ -0,0 +1,256 @@
+package util
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 群机器人类型
+const (
+	RobotDingTalk = "dingtalk"
+	RobotWeCom    = "wecom"
+	RobotFeishu   = "feishu"
+)
+
+// 群机器人消息类型
+const (
+	RobotMsgText     = "text"
+	RobotMsgMarkdown = "markdown"
+)
+
+// RobotConfig 群机器人配置结构
+type RobotConfig struct {
+	Webhook   string   `json:"webhook"`    // 机器人Webhook地址
+	Secret    string   `json:"secret"`     // 加签密钥(钉钉、飞书)，为空时不签名
+	MsgType   string   `json:"msg_type"`   // 消息类型 text/markdown，默认text
+	AtMobiles []string `json:"at_mobiles"` // 需要@的手机号
+	AtAll     bool     `json:"at_all"`     // 是否@所有人
+	Timeout   int      `json:"timeout"`    // 超时时间(秒)
+}
+
+// robotResponse 群机器人接口响应，钉钉和企业微信使用errcode，飞书使用code
+type robotResponse struct {
+	ErrCode int    `json:"errcode"`
+	ErrMsg  string `json:"errmsg"`
+	Code    int    `json:"code"`
+	Msg     string `json:"msg"`
+}
+
+// ValidateRobotConfig 校验群机器人配置
+func ValidateRobotConfig(robotType string, config RobotConfig) error {
+	switch robotType {
+	case RobotDingTalk, RobotWeCom, RobotFeishu:
+	default:
+		return fmt.Errorf("不支持的机器人类型: %s", robotType)
+	}
+
+	if !strings.HasPrefix(config.Webhook, "https://") && !strings.HasPrefix(config.Webhook, "http://") {
+		return fmt.Errorf("机器人Webhook地址必须以http://或https://开头")
+	}
+
+	switch config.MsgType {
+	case "", RobotMsgText, RobotMsgMarkdown:
+	default:
+		return fmt.Errorf("不支持的消息类型: %s", config.MsgType)
+	}
+
+	for _, mobile := range config.AtMobiles {
+		if !VerifyMobileFormat(mobile) {
+			return fmt.Errorf("手机号格式错误: %s", mobile)
+		}
+	}
+
+	return nil
+}
+
+// SendRobot 通过群机器人发送告警
+func SendRobot(ctx context.Context, robotType string, config RobotConfig, alert Alert) error {
+	var payload map[string]interface{}
+	var err error
+	target := config.Webhook
+
+	switch robotType {
+	case RobotDingTalk:
+		payload = dingTalkPayload(config, alert)
+		if config.Secret != "" {
+			target, err = dingTalkSignedURL(config.Webhook, config.Secret, time.Now())
+		}
+	case RobotWeCom:
+		payload = weComPayload(config, alert)
+	case RobotFeishu:
+		payload = feishuPayload(config, alert)
+		if config.Secret != "" {
+			timestamp := time.Now().Unix()
+			payload["timestamp"] = strconv.FormatInt(timestamp, 10)
+			payload["sign"], err = FeishuSign(config.Secret, timestamp)
+		}
+	default:
+		return fmt.Errorf("不支持的机器人类型: %s", robotType)
+	}
+	if err != nil {
+		return fmt.Errorf("生成签名失败: %v", err)
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("构造消息失败: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+	body, err := DoHTTPRequest(NewHTTPClient(config.Timeout, false), req)
+	if err != nil {
+		return err
+	}
+
+	// 机器人接口即使失败也返回200，需要检查响应内容
+	var resp robotResponse
+	if err = json.Unmarshal(body, &resp); err != nil {
+		return fmt.Errorf("解析响应失败: %v", err)
+	}
+	if resp.ErrCode != 0 {
+		return fmt.Errorf("机器人返回错误[%d]: %s", resp.ErrCode, resp.ErrMsg)
+	}
+	if resp.Code != 0 {
+		return fmt.Errorf("机器人返回错误[%d]: %s", resp.Code, resp.Msg)
+	}
+
+	return nil
+}
+
+// SendTestRobot 通过群机器人发送测试消息
+func SendTestRobot(ctx context.Context, robotType string, config RobotConfig, systemName string) error {
+	alert := NewAlert("test", "测试消息", SeverityInfo, "这是一条测试消息，用于验证机器人配置是否正确。")
+	alert.SystemName = systemName
+	return SendRobot(ctx, robotType, config, alert)
+}
+
+// DingTalkSign 钉钉加签，将 timestamp+"\n"+secret 使用HmacSHA256计算签名后进行Base64和URL编码
+func DingTalkSign(secret string, timestamp int64) (string, error) {
+	sign, err := HmacSHA256Base64Sign(secret, fmt.Sprintf("%d\n%s", timestamp, secret))
+	if err != nil {
+		return "", err
+	}
+	return url.QueryEscape(sign), nil
+}
+
+// FeishuSign 飞书加签，以 timestamp+"\n"+secret 作为密钥对空字符串计算HmacSHA256后进行Base64编码
+func FeishuSign(secret string, timestamp int64) (string, error) {
+	return HmacSHA256Base64Sign(fmt.Sprintf("%d\n%s", timestamp, secret), "")
+}
+
+// dingTalkSignedURL 为钉钉Webhook地址追加timestamp和sign参数
+func dingTalkSignedURL(webhook, secret string, now time.Time) (string, error) {
+	timestamp := now.UnixMilli()
+	sign, err := DingTalkSign(secret, timestamp)
+	if err != nil {
+		return "", err
+	}
+
+	separator := "?"
+	if strings.Contains(webhook, "?") {
+		separator = "&"
+	}
+	return fmt.Sprintf("%s%stimestamp=%d&sign=%s", webhook, separator, timestamp, sign), nil
+}
+
+func dingTalkPayload(config RobotConfig, alert Alert) map[string]interface{} {
+	at := map[string]interface{}{
+		"atMobiles": config.AtMobiles,
+		"isAtAll":   config.AtAll,
+	}
+
+	if config.MsgType == RobotMsgMarkdown {
+		// 钉钉markdown消息需要在正文中包含@手机号才会生效
+		text := robotMarkdown(alert) + mentionText(config.AtMobiles)
+		return map[string]interface{}{
+			"msgtype":  "markdown",
+			"markdown": map[string]string{"title": alert.Subject(), "text": text},
+			"at":       at,
+		}
+	}
+
+	return map[string]interface{}{
+		"msgtype": "text",
+		"text":    map[string]string{"content": robotText(alert)},
+		"at":      at,
+	}
+}
+
+func weComPayload(config RobotConfig, alert Alert) map[string]interface{} {
+	if config.MsgType == RobotMsgMarkdown {
+		// 企业微信markdown消息不支持按手机号@成员
+		return map[string]interface{}{
+			"msgtype":  "markdown",
+			"markdown": map[string]string{"content": robotMarkdown(alert)},
+		}
+	}
+
+	mobiles := append([]string{}, config.AtMobiles...)
+	if config.AtAll {
+		mobiles = append(mobiles, "@all")
+	}
+	return map[string]interface{}{
+		"msgtype": "text",
+		"text": map[string]interface{}{
+			"content":               robotText(alert),
+			"mentioned_mobile_list": mobiles,
+		},
+	}
+}
+
+func feishuPayload(config RobotConfig, alert Alert) map[string]interface{} {
+	// 飞书自定义机器人不支持按手机号@成员，仅支持@所有人
+	mention := ""
+	if config.AtAll {
+		mention = "\n<at user_id=\"all\">所有人</at>"
+	}
+
+	if config.MsgType == RobotMsgMarkdown {
+		return map[string]interface{}{
+			"msg_type": "interactive",
+			"card": map[string]interface{}{
+				"header": map[string]interface{}{
+					"title": map[string]string{"tag": "plain_text", "content": alert.Subject()},
+				},
+				"elements": []map[string]string{
+					{"tag": "markdown", "content": robotMarkdown(alert) + mention},
+				},
+			},
+		}
+	}
+
+	return map[string]interface{}{
+		"msg_type": "text",
+		"content":  map[string]string{"text": robotText(alert) + mention},
+	}
+}
+
+// robotText 生成文本格式的告警内容
+func robotText(alert Alert) string {
+	return fmt.Sprintf("%s\n级别: %s\n时间: %s\n%s", alert.Subject(), alert.Severity, alert.Time(), alert.Message)
+}
+
+// robotMarkdown 生成markdown格式的告警内容
+func robotMarkdown(alert Alert) string {
+	return fmt.Sprintf("### %s\n> 级别: %s\n\n> 时间: %s\n\n%s", alert.Subject(), alert.Severity, alert.Time(), alert.Message)
+}
+
+// mentionText 生成@手机号文本
+func mentionText(mobiles []string) string {
+	if len(mobiles) == 0 {
+		return ""
+	}
+	return "\n\n@" + strings.Join(mobiles, " @")
+}
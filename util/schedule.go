@@ -0,0 +1,77 @@
+package util
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// scheduleMaxJitter 随机延迟的最大秒数
+const scheduleMaxJitter = 3600
+
+// ScheduleConfig 定时执行配置，设置cron表达式时代替固定的执行间隔
+type ScheduleConfig struct {
+	Cron         string `json:"cron"`           // cron表达式，支持5个或6个(带秒)字段
+	Timezone     string `json:"timezone"`       // cron表达式使用的时区，如Asia/Shanghai，为空时使用本地时区
+	Jitter       int    `json:"jitter"`         // 每次执行前随机延迟的最大秒数，避免多个任务同时执行
+	RunOnStartup bool   `json:"run_on_startup"` // 启动时立即执行一次
+}
+
+// Validate 校验定时执行配置
+func (c ScheduleConfig) Validate() error {
+	if c.Jitter < 0 || c.Jitter > scheduleMaxJitter {
+		return fmt.Errorf("随机延迟必须在0到%d秒之间", scheduleMaxJitter)
+	}
+	if _, err := c.location(); err != nil {
+		return err
+	}
+	if c.Cron != "" {
+		if _, err := c.cron(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c ScheduleConfig) location() (*time.Location, error) {
+	if c.Timezone == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("时区%s错误: %v", c.Timezone, err)
+	}
+	return loc, nil
+}
+
+func (c ScheduleConfig) cron() (*CronSchedule, error) {
+	loc, err := c.location()
+	if err != nil {
+		return nil, err
+	}
+	return ParseCron(c.Cron, loc)
+}
+
+// Next 返回from之后的下一次执行时间，设置了cron表达式时按表达式计算，否则按interval计算，不定时执行时返回零值
+func (c ScheduleConfig) Next(interval time.Duration, from time.Time) time.Time {
+	if c.Cron == "" {
+		if interval <= 0 {
+			return time.Time{}
+		}
+		return from.Add(interval)
+	}
+
+	schedule, err := c.cron()
+	if err != nil {
+		return time.Time{}
+	}
+	return schedule.Next(from)
+}
+
+// JitterDelay 返回本次执行的随机延迟
+func (c ScheduleConfig) JitterDelay() time.Duration {
+	if c.Jitter <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(c.Jitter)*int64(time.Second) + 1))
+}
@@ -5,19 +5,75 @@ import (
 	"fmt"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 )
 
+// DefaultScriptName 旧版本单个脚本配置迁移后的脚本名称，旧的脚本配置接口读写该脚本
+const DefaultScriptName = "default"
+
+// 脚本执行模式
+const (
+	ScriptModeValue  = "value"  // 脚本输出整数作为返回值，非0退出码视为执行失败
+	ScriptModeNagios = "nagios" // Nagios插件，退出码作为返回值，第一行输出为状态文本，|之后为性能数据
+)
+
 // ScriptConfig 脚本配置结构
 type ScriptConfig struct {
 	Path       string `json:"path"`
 	Parameters string `json:"parameters"`
 	Timeout    int    `json:"timeout"`  // 超时时间(秒)
-	Interval   int    `json:"interval"` // 执行间隔(分钟)
+	Interval   int    `json:"interval"` // 执行间隔(分钟)，0且未设置cron表达式时不自动执行
+	Mode       string `json:"mode"`     // 执行模式 value/nagios，默认value
+
+	Schedule ScheduleConfig `json:"schedule"` // 定时执行配置
+}
+
+// Script 定时执行的脚本，按返回值告警
+type Script struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	ScriptConfig
+	Returns   map[int]string `json:"returns"` // 返回值对应的告警文本，返回0表示正常；Nagios模式未配置时使用插件输出的状态文本
+	Enabled   bool           `json:"enabled"`
+	CreatedAt string         `json:"created_at"`
+}
+
+// Validate 校验脚本配置
+func (s Script) Validate() error {
+	if s.Name == "" {
+		return fmt.Errorf("脚本名称不能为空")
+	}
+	if s.Path == "" {
+		return fmt.Errorf("脚本路径不能为空")
+	}
+	if s.Timeout < 0 || s.Interval < 0 {
+		return fmt.Errorf("超时时间和执行间隔不能为负数")
+	}
+	if _, exists := s.Returns[0]; exists {
+		return fmt.Errorf("返回值0表示正常，不能配置告警文本")
+	}
+	switch s.Mode {
+	case "", ScriptModeValue:
+	case ScriptModeNagios:
+		for value := range s.Returns {
+			if value < NagiosWarning || value > NagiosUnknown {
+				return fmt.Errorf("Nagios模式的返回值只能为1、2、3")
+			}
+		}
+	default:
+		return fmt.Errorf("不支持的执行模式: %s", s.Mode)
+	}
+	return s.Schedule.Validate()
+}
+
+// Scheduled 是否定时执行
+func (s Script) Scheduled() bool {
+	return s.Interval > 0 || s.Schedule.Cron != ""
 }
 
-// ExecuteScript 执行脚本
+// ExecuteScript 执行脚本，Nagios模式下返回插件的退出码和标准输出
 func ExecuteScript(config ScriptConfig) (int, string, error) {
 	// 检查脚本路径是否为空
 	if config.Path == "" {
@@ -49,6 +105,10 @@ func ExecuteScript(config ScriptConfig) (int, string, error) {
 		cmd = exec.CommandContext(ctx, cmd.Path, cmd.Args[1:]...)
 	}
 
+	if config.Mode == ScriptModeNagios {
+		return runNagiosPlugin(cmd)
+	}
+
 	// 执行命令
 	output, err := cmd.CombinedOutput()
 	outputStr := string(output)
@@ -69,3 +129,119 @@ func ExecuteScript(config ScriptConfig) (int, string, error) {
 
 	return resultInt, outputStr, nil
 }
+
+// ScriptMonitor 根据脚本返回值发送告警和恢复通知
+type ScriptMonitor struct {
+	notifier CheckNotifier
+}
+
+// NewScriptMonitor 创建脚本告警处理器
+func NewScriptMonitor(tracker *AlertTracker, alertFunc func(Alert) error) *ScriptMonitor {
+	return &ScriptMonitor{
+		notifier: CheckNotifier{
+			Source:    AlertSourceScript,
+			Title:     "脚本执行告警",
+			Tracker:   tracker,
+			AlertFunc: alertFunc,
+		},
+	}
+}
+
+// Handle 根据脚本返回值发送告警或恢复通知
+// 返回值为0时正常，此前处于告警状态的返回值发送恢复通知；非0且配置了告警文本时发送告警
+// Nagios模式下WARNING、CRITICAL、UNKNOWN都发送告警，未配置告警文本时使用插件输出的状态文本
+func (m *ScriptMonitor) Handle(s Script, result int, output string, now time.Time) error {
+	alertText := s.Returns[result]
+	severity := SeverityWarning
+	if s.Mode == ScriptModeNagios && result != NagiosOK {
+		if alertText == "" {
+			alertText, _ = ParseNagiosOutput(output)
+		}
+		if alertText == "" {
+			alertText = NagiosStatusName(result)
+		}
+		severity = nagiosSeverity(result)
+	}
+	firing := result != 0 && alertText != ""
+
+	// 未配置告警文本的非0返回值(如脚本执行失败)不改变告警状态
+	if result != 0 && !firing {
+		return nil
+	}
+
+	// 返回值恢复为0或变为其他告警返回值时，之前的返回值视为已恢复
+	var errs []string
+	kind := s.resultKind()
+	prefix := strconv.Itoa(s.ID) + ":"
+	for _, state := range m.notifier.Tracker.Firing() {
+		value, isScript := strings.CutPrefix(state.Key, prefix)
+		if !isScript || (firing && value == strconv.Itoa(result)) {
+			continue
+		}
+		previous, _ := strconv.Atoi(value)
+		msg := fmt.Sprintf("脚本%s%s已恢复为%s，此前%s%s", s.Name, kind, s.resultName(0), kind, s.resultName(previous))
+		if result != 0 {
+			msg = fmt.Sprintf("脚本%s%s已由%s变为%s", s.Name, kind, s.resultName(previous), s.resultName(result))
+		}
+		if text := s.Returns[previous]; text != "" {
+			msg += "，此前告警: " + text
+		}
+		if err := m.notifier.Resolve(state.Key, msg); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if firing {
+		check := thresholdCheck{
+			Key:      prefix + strconv.Itoa(result),
+			Name:     "脚本" + s.Name,
+			Value:    kind + s.resultName(result),
+			Breach:   fmt.Sprintf("脚本%s%s%s: %s", s.Name, kind, s.resultName(result), alertText),
+			Severity: severity,
+		}
+		if err := m.notifier.Update(check, now); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// resultKind 告警消息中返回值的称呼
+func (s Script) resultKind() string {
+	if s.Mode == ScriptModeNagios {
+		return "状态"
+	}
+	return "返回值"
+}
+
+// resultName 告警消息中的返回值，Nagios模式为状态名称
+func (s Script) resultName(result int) string {
+	if s.Mode == ScriptModeNagios {
+		return NagiosStatusName(result)
+	}
+	return strconv.Itoa(result)
+}
+
+// Prune 已删除或停用的脚本、已删除告警文本的返回值处于告警状态时发送恢复通知
+func (m *ScriptMonitor) Prune(active []Script) error {
+	keys := make(map[string]bool)
+	for _, s := range active {
+		if s.Mode == ScriptModeNagios {
+			for value := NagiosWarning; value <= NagiosUnknown; value++ {
+				keys[strconv.Itoa(s.ID)+":"+strconv.Itoa(value)] = true
+			}
+			continue
+		}
+		for value, text := range s.Returns {
+			if text != "" {
+				keys[strconv.Itoa(s.ID)+":"+strconv.Itoa(value)] = true
+			}
+		}
+	}
+
+	return m.notifier.Prune(func(key string) bool { return keys[key] }, "脚本或返回值告警配置已删除或停用")
+}
@@ -0,0 +1,248 @@
+package util
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	smtpCommandTimeout = 5 * time.Minute // 等待客户端命令的超时时间
+	smtpMaxRecipients  = 100             // 单封邮件最多的收件人数
+	smtpMaxLineLength  = 1000            // 单行命令的最大字节数，超过时断开连接
+	smtpMaxConns       = 100             // 最多同时处理的连接数，超过时拒绝新连接
+)
+
+// SMTPServer 只接收邮件的简单SMTP服务，不支持认证和转发
+type SMTPServer struct {
+	MaxSize int64                                             // 单封邮件最大字节数
+	Accept  func(rcpt string) bool                            // 是否接收发往该地址的邮件
+	Handle  func(from string, to []string, data []byte) error // 邮件处理函数，返回错误时拒收
+
+	mu       sync.Mutex
+	listener net.Listener
+	conns    map[net.Conn]bool
+	closed   bool
+}
+
+// NewSMTPServer 创建SMTP服务
+func NewSMTPServer(maxSize int64, accept func(rcpt string) bool, handle func(from string, to []string, data []byte) error) *SMTPServer {
+	return &SMTPServer{MaxSize: maxSize, Accept: accept, Handle: handle, conns: make(map[net.Conn]bool)}
+}
+
+// Listen 在指定地址监听
+func (s *SMTPServer) Listen(address string) error {
+	l, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("监听SMTP %s失败: %v", address, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return l.Close()
+	}
+	s.listener = l
+	go s.serve(l)
+	return nil
+}
+
+// Close 关闭监听和所有连接
+func (s *SMTPServer) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	if s.listener != nil {
+		s.listener.Close()
+	}
+	for conn := range s.conns {
+		conn.Close()
+	}
+}
+
+func (s *SMTPServer) serve(l net.Listener) {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			conn.Close()
+			return
+		}
+		if len(s.conns) >= smtpMaxConns {
+			s.mu.Unlock()
+			conn.SetWriteDeadline(time.Now().Add(time.Second))
+			fmt.Fprint(conn, "421 Too many connections\r\n")
+			conn.Close()
+			continue
+		}
+		s.conns[conn] = true
+		s.mu.Unlock()
+
+		go func() {
+			defer func() {
+				s.mu.Lock()
+				delete(s.conns, conn)
+				s.mu.Unlock()
+				conn.Close()
+			}()
+			s.serveConn(conn)
+		}()
+	}
+}
+
+// smtpSession 单个连接中当前邮件的信封
+type smtpSession struct {
+	from string
+	to   []string
+	mail bool // 是否已收到MAIL命令
+}
+
+func (s *SMTPServer) serveConn(conn net.Conn) {
+	// 读缓冲区大小即单行命令的最大长度，避免客户端发送不换行的数据占用内存
+	br := bufio.NewReaderSize(conn, smtpMaxLineLength)
+	tw := textproto.NewWriter(bufio.NewWriter(conn))
+	hostname, _ := os.Hostname()
+	reply := func(format string, args ...interface{}) error {
+		return tw.PrintfLine(format, args...)
+	}
+
+	if err := reply("220 %s ESMTP warnnotice", hostname); err != nil {
+		return
+	}
+
+	var session smtpSession
+	for {
+		conn.SetDeadline(time.Now().Add(smtpCommandTimeout))
+		data, err := br.ReadSlice('\n')
+		if err == bufio.ErrBufferFull {
+			reply("500 Line too long")
+			return
+		}
+		if err != nil {
+			return
+		}
+		line := strings.TrimRight(string(data), "\r\n")
+		verb, arg, _ := strings.Cut(line, " ")
+		arg = strings.TrimSpace(arg)
+
+		switch strings.ToUpper(verb) {
+		case "HELO":
+			session = smtpSession{}
+			err = reply("250 %s", hostname)
+		case "EHLO":
+			session = smtpSession{}
+			err = reply("250-%s\r\n250-SIZE %d\r\n250 8BITMIME", hostname, s.MaxSize)
+		case "MAIL":
+			from, params, ok := smtpPath(arg, "FROM:")
+			switch {
+			case !ok:
+				err = reply("501 Syntax: MAIL FROM:<address>")
+			case smtpSizeExceeded(params, s.MaxSize):
+				err = reply("552 Message size exceeds maximum")
+			default:
+				session = smtpSession{from: from, mail: true}
+				err = reply("250 OK")
+			}
+		case "RCPT":
+			rcpt, _, ok := smtpPath(arg, "TO:")
+			switch {
+			case !ok:
+				err = reply("501 Syntax: RCPT TO:<address>")
+			case !session.mail:
+				err = reply("503 Need MAIL command")
+			case len(session.to) >= smtpMaxRecipients:
+				err = reply("452 Too many recipients")
+			case !s.Accept(rcpt):
+				err = reply("550 No such user here")
+			default:
+				session.to = append(session.to, rcpt)
+				err = reply("250 OK")
+			}
+		case "DATA":
+			if len(session.to) == 0 {
+				err = reply("503 Need RCPT command")
+				break
+			}
+			if err = reply("354 End data with <CR><LF>.<CR><LF>"); err != nil {
+				return
+			}
+			err = s.receive(textproto.NewReader(br), session, reply)
+			session = smtpSession{}
+		case "RSET":
+			session = smtpSession{}
+			err = reply("250 OK")
+		case "NOOP":
+			err = reply("250 OK")
+		case "VRFY":
+			err = reply("252 Cannot VRFY user")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			err = reply("502 Command not implemented")
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// receive 读取DATA内容并交给处理函数
+func (s *SMTPServer) receive(tr *textproto.Reader, session smtpSession, reply func(string, ...interface{}) error) error {
+	dot := tr.DotReader()
+	data, err := io.ReadAll(io.LimitReader(dot, s.MaxSize+1))
+	if err != nil {
+		return err
+	}
+	if int64(len(data)) > s.MaxSize {
+		// 读完剩余内容后再拒收
+		if _, err = io.Copy(io.Discard, dot); err != nil {
+			return err
+		}
+		return reply("552 Message size exceeds maximum")
+	}
+
+	if err = s.Handle(session.from, session.to, data); err != nil {
+		return reply("554 Transaction failed")
+	}
+	return reply("250 OK: queued")
+}
+
+// smtpPath 解析 FROM:<address> 或 TO:<address> 形式的参数
+func smtpPath(arg, prefix string) (string, string, bool) {
+	if len(arg) < len(prefix) || !strings.EqualFold(arg[:len(prefix)], prefix) {
+		return "", "", false
+	}
+	rest := strings.TrimSpace(arg[len(prefix):])
+	if !strings.HasPrefix(rest, "<") {
+		return "", "", false
+	}
+	end := strings.IndexByte(rest, '>')
+	if end < 0 {
+		return "", "", false
+	}
+	return rest[1:end], strings.TrimSpace(rest[end+1:]), true
+}
+
+// smtpSizeExceeded 判断MAIL命令的SIZE参数是否超过最大大小
+func smtpSizeExceeded(params string, maxSize int64) bool {
+	for _, param := range strings.Fields(params) {
+		if name, value, found := strings.Cut(param, "="); found && strings.EqualFold(name, "SIZE") {
+			size, err := strconv.ParseInt(value, 10, 64)
+			return err == nil && size > maxSize
+		}
+	}
+	return false
+}
@@ -0,0 +1,434 @@
+package util
+
+import (
+	"fmt"
+	"net"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AlertSourceSyslog syslog告警来源
+const AlertSourceSyslog = "syslog"
+
+// syslog监听协议
+const (
+	SyslogProtocolUDP  = "udp"
+	SyslogProtocolTCP  = "tcp"
+	SyslogProtocolBoth = "both"
+)
+
+const (
+	syslogMaxMessageLen = 2048 // 保存的消息内容最大长度
+	syslogContextLines  = 10   // 告警内容中附带的最近匹配消息数
+	syslogMaxMatches    = 100  // 每条规则在时间窗口内最多保留的匹配消息数
+)
+
+// syslogFacilities 设施名称，下标为设施编号
+var syslogFacilities = []string{
+	"kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news",
+	"uucp", "cron", "authpriv", "ftp", "ntp", "security", "console", "clock",
+	"local0", "local1", "local2", "local3", "local4", "local5", "local6", "local7",
+}
+
+// syslogSeverities 消息级别名称，下标为级别编号，编号越小越严重
+var syslogSeverities = []string{"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug"}
+
+// SyslogConfig syslog接收配置
+type SyslogConfig struct {
+	Enabled     bool   `json:"enabled"`
+	Protocol    string `json:"protocol"`     // 监听协议 udp/tcp/both
+	Address     string `json:"address"`      // 监听地址，如 :514
+	MaxMessages int    `json:"max_messages"` // 保留的最近消息数
+}
+
+// DefaultSyslogConfig 默认syslog接收配置
+func DefaultSyslogConfig() SyslogConfig {
+	return SyslogConfig{
+		Protocol:    SyslogProtocolUDP,
+		Address:     ":514",
+		MaxMessages: 1000,
+	}
+}
+
+// Validate 校验syslog接收配置
+func (c SyslogConfig) Validate() error {
+	switch c.Protocol {
+	case SyslogProtocolUDP, SyslogProtocolTCP, SyslogProtocolBoth:
+	default:
+		return fmt.Errorf("不支持的监听协议: %s", c.Protocol)
+	}
+	if _, _, err := net.SplitHostPort(c.Address); err != nil {
+		return fmt.Errorf("监听地址%s格式错误: %v", c.Address, err)
+	}
+	if c.MaxMessages <= 0 || c.MaxMessages > 100000 {
+		return fmt.Errorf("保留消息数必须在1到100000之间")
+	}
+	return nil
+}
+
+// SyslogMessage 收到的syslog消息
+type SyslogMessage struct {
+	ID         int    `json:"id"`
+	Timestamp  int64  `json:"timestamp"`   // 消息中的时间，没有时使用接收时间
+	ReceivedAt int64  `json:"received_at"` // 接收时间
+	RemoteAddr string `json:"remote_addr"`
+	Facility   string `json:"facility"`
+	Severity   string `json:"severity"`
+	Hostname   string `json:"hostname"`
+	AppName    string `json:"app_name"`
+	Message    string `json:"message"`
+	Rules      string `json:"rules"` // 匹配的规则名称，多个以逗号分隔
+}
+
+// ParseSyslog 解析RFC 5424或RFC 3164格式的syslog消息，无法识别的部分作为消息内容
+func ParseSyslog(data []byte, remoteAddr string, now time.Time) SyslogMessage {
+	msg := SyslogMessage{
+		Timestamp:  now.Unix(),
+		ReceivedAt: now.Unix(),
+		RemoteAddr: remoteAddr,
+		Facility:   syslogFacilities[1],
+		Severity:   syslogSeverities[5],
+	}
+	rest := strings.TrimRight(string(data), "\r\n\x00")
+
+	// 没有PRI时按RFC 3164的约定视为user.notice
+	if strings.HasPrefix(rest, "<") {
+		if end := strings.IndexByte(rest, '>'); end > 1 && end <= 4 {
+			if pri, err := strconv.Atoi(rest[1:end]); err == nil && pri >= 0 && pri < len(syslogFacilities)*8 {
+				msg.Facility, msg.Severity = syslogFacilities[pri/8], syslogSeverities[pri%8]
+				rest = rest[end+1:]
+			}
+		}
+	}
+
+	if strings.HasPrefix(rest, "1 ") {
+		parseRFC5424(&msg, rest[2:])
+	} else {
+		parseRFC3164(&msg, rest, now)
+	}
+
+	if msg.Hostname == "" {
+		msg.Hostname = remoteAddr
+		if host, _, err := net.SplitHostPort(remoteAddr); err == nil {
+			msg.Hostname = host
+		}
+	}
+	msg.Message = truncate(msg.Message, syslogMaxMessageLen)
+	return msg
+}
+
+// parseRFC5424 解析 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG
+func parseRFC5424(msg *SyslogMessage, rest string) {
+	fields := strings.SplitN(rest, " ", 6)
+	for len(fields) < 6 {
+		fields = append(fields, "")
+	}
+	nilValue := func(s string) string {
+		if s == "-" {
+			return ""
+		}
+		return s
+	}
+	if t, err := time.Parse(time.RFC3339Nano, fields[0]); err == nil {
+		msg.Timestamp = t.Unix()
+	}
+	msg.Hostname = nilValue(fields[1])
+	msg.AppName = nilValue(fields[2])
+
+	// 跳过结构化数据，参数值中可能包含转义的]
+	content := fields[5]
+	if strings.HasPrefix(content, "-") {
+		content = content[1:]
+	} else if strings.HasPrefix(content, "[") {
+		inQuote, escaped := false, false
+		end := len(content)
+		for i := 0; i < len(content); i++ {
+			switch ch := content[i]; {
+			case escaped:
+				escaped = false
+			case ch == '\\':
+				escaped = true
+			case ch == '"':
+				inQuote = !inQuote
+			case ch == ']' && !inQuote && (i+1 == len(content) || content[i+1] != '['):
+				end = i + 1
+			}
+			if end < len(content) {
+				break
+			}
+		}
+		content = content[end:]
+	}
+	msg.Message = strings.TrimPrefix(strings.TrimPrefix(content, " "), "\xEF\xBB\xBF")
+}
+
+// parseRFC3164 解析 TIMESTAMP HOSTNAME TAG: MSG，时间戳缺失时整条作为内容
+func parseRFC3164(msg *SyslogMessage, rest string, now time.Time) {
+	hasHeader := false
+	if len(rest) > 16 && rest[15] == ' ' {
+		if t, err := time.ParseInLocation(time.Stamp, rest[:15], now.Location()); err == nil {
+			// 时间戳没有年份，使用当前年份，跨年时使用上一年
+			t = t.AddDate(now.Year(), 0, 0)
+			if t.After(now.Add(24 * time.Hour)) {
+				t = t.AddDate(-1, 0, 0)
+			}
+			msg.Timestamp = t.Unix()
+			rest, hasHeader = rest[16:], true
+		}
+	}
+	if !hasHeader {
+		// 部分设备使用RFC 3339格式的时间戳
+		if ts, remain, found := strings.Cut(rest, " "); found {
+			if t, err := time.Parse(time.RFC3339Nano, ts); err == nil {
+				msg.Timestamp = t.Unix()
+				rest, hasHeader = remain, true
+			}
+		}
+	}
+	if hasHeader {
+		if host, remain, found := strings.Cut(rest, " "); found && !strings.HasSuffix(host, ":") {
+			msg.Hostname, rest = host, remain
+		}
+	}
+
+	// TAG由字母数字组成，可带[pid]，以冒号结束
+	if tag, remain, found := strings.Cut(rest, ":"); found && tag != "" && !strings.ContainsAny(tag, " \t") {
+		if i := strings.IndexByte(tag, '['); i > 0 {
+			tag = tag[:i]
+		}
+		msg.AppName, rest = tag, strings.TrimPrefix(remain, " ")
+	}
+	msg.Message = rest
+}
+
+// SyslogRule syslog匹配规则，时间窗口内匹配次数达到阈值时告警
+type SyslogRule struct {
+	ID          int      `json:"id"`
+	Name        string   `json:"name"`
+	Facilities  []string `json:"facilities"`   // 设施，为空时匹配所有设施
+	MinSeverity string   `json:"min_severity"` // 最低消息级别，如err匹配emerg、alert、crit和err，为空时匹配所有级别
+	Hostname    string   `json:"hostname"`     // 主机名，支持通配符，为空时匹配所有主机
+	Pattern     string   `json:"pattern"`      // 消息内容正则，为空时匹配所有内容
+	Threshold   int      `json:"threshold"`    // 匹配次数阈值，默认1
+	Window      int      `json:"window"`       // 时间窗口(秒)，默认60
+	Severity    string   `json:"severity"`     // 告警级别，默认warning
+	Enabled     bool     `json:"enabled"`
+	CreatedAt   string   `json:"created_at"`
+}
+
+// Validate 校验syslog匹配规则并补充默认值
+func (r *SyslogRule) Validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("规则名称不能为空")
+	}
+	for _, facility := range r.Facilities {
+		if !slices.Contains(syslogFacilities, facility) {
+			return fmt.Errorf("不支持的设施: %s", facility)
+		}
+	}
+	if r.MinSeverity != "" && !slices.Contains(syslogSeverities, r.MinSeverity) {
+		return fmt.Errorf("不支持的消息级别: %s", r.MinSeverity)
+	}
+	if _, err := path.Match(r.Hostname, ""); err != nil {
+		return fmt.Errorf("主机名%s格式错误: %v", r.Hostname, err)
+	}
+	if _, err := regexp.Compile(r.Pattern); err != nil {
+		return fmt.Errorf("消息正则格式错误: %v", err)
+	}
+	if r.Threshold < 0 || r.Window < 0 {
+		return fmt.Errorf("匹配次数和时间窗口不能为负数")
+	}
+	if r.Threshold == 0 {
+		r.Threshold = 1
+	}
+	if r.Window == 0 {
+		r.Window = 60
+	}
+	if r.Severity != "" && !ValidSeverity(r.Severity) {
+		return fmt.Errorf("告警级别错误: %s", r.Severity)
+	}
+	return nil
+}
+
+// syslogMatcher 编译后的匹配规则
+type syslogMatcher struct {
+	rule SyslogRule
+	re   *regexp.Regexp
+}
+
+func newSyslogMatcher(r SyslogRule) (syslogMatcher, error) {
+	re, err := regexp.Compile(r.Pattern)
+	if err != nil {
+		return syslogMatcher{}, fmt.Errorf("规则%s正则格式错误: %v", r.Name, err)
+	}
+	return syslogMatcher{rule: r, re: re}, nil
+}
+
+func (m syslogMatcher) match(msg SyslogMessage) bool {
+	r := m.rule
+	if len(r.Facilities) > 0 && !slices.Contains(r.Facilities, msg.Facility) {
+		return false
+	}
+	if r.MinSeverity != "" && slices.Index(syslogSeverities, msg.Severity) > slices.Index(syslogSeverities, r.MinSeverity) {
+		return false
+	}
+	if r.Hostname != "" {
+		if matched, _ := path.Match(r.Hostname, msg.Hostname); !matched {
+			return false
+		}
+	}
+	return m.re.MatchString(msg.Message)
+}
+
+// MatchSyslogMessages 返回匹配规则的消息，用于测试匹配规则
+func MatchSyslogMessages(r SyslogRule, messages []SyslogMessage) ([]SyslogMessage, error) {
+	m, err := newSyslogMatcher(r)
+	if err != nil {
+		return nil, err
+	}
+	matched := make([]SyslogMessage, 0)
+	for _, msg := range messages {
+		if m.match(msg) {
+			matched = append(matched, msg)
+		}
+	}
+	return matched, nil
+}
+
+// SyslogMonitor 按规则匹配收到的syslog消息并告警，发送通知可能较慢，在锁外进行
+type SyslogMonitor struct {
+	mu       sync.Mutex
+	matchers []syslogMatcher
+	matches  map[int][]logMatch // 各规则时间窗口内的匹配
+	notifier CheckNotifier
+}
+
+// NewSyslogMonitor 创建syslog监控器
+func NewSyslogMonitor(tracker *AlertTracker, alertFunc func(Alert) error) *SyslogMonitor {
+	return &SyslogMonitor{
+		matches: make(map[int][]logMatch),
+		notifier: CheckNotifier{
+			Source:    AlertSourceSyslog,
+			Title:     "Syslog告警",
+			Tracker:   tracker,
+			AlertFunc: alertFunc,
+		},
+	}
+}
+
+// SetRules 更新匹配规则，已删除的规则处于告警状态时发送恢复通知
+func (m *SyslogMonitor) SetRules(rules []SyslogRule) error {
+	m.mu.Lock()
+	var errs []string
+	matchers := make([]syslogMatcher, 0, len(rules))
+	keys := make(map[string]bool, len(rules))
+	for _, r := range rules {
+		matcher, err := newSyslogMatcher(r)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		matchers = append(matchers, matcher)
+		keys[strconv.Itoa(r.ID)] = true
+	}
+	m.matchers = matchers
+
+	for id := range m.matches {
+		if !keys[strconv.Itoa(id)] {
+			delete(m.matches, id)
+		}
+	}
+	m.mu.Unlock()
+
+	if err := m.notifier.Prune(func(key string) bool { return keys[key] }, "Syslog规则已删除或停用"); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// Handle 按规则匹配消息，记录匹配的规则名称并更新告警状态
+func (m *SyslogMonitor) Handle(msg *SyslogMessage, now time.Time) error {
+	m.mu.Lock()
+	var names []string
+	var checks []thresholdCheck
+	line := truncate(fmt.Sprintf("%s %s.%s %s: %s", msg.Hostname, msg.Facility, msg.Severity, msg.AppName, msg.Message), logMaxLineLen)
+	for _, matcher := range m.matchers {
+		if !matcher.match(*msg) {
+			continue
+		}
+		r := matcher.rule
+		names = append(names, r.Name)
+		matches := append(m.matches[r.ID], logMatch{Time: now, Line: line})
+		m.matches[r.ID] = matches[max(len(matches)-max(r.Threshold, syslogMaxMatches), 0):]
+		checks = append(checks, m.ruleCheck(r, now))
+	}
+	m.mu.Unlock()
+
+	msg.Rules = strings.Join(names, ",")
+	return m.update(checks, now)
+}
+
+// Evaluate 检查各规则时间窗口内的匹配次数，用于在没有新消息时恢复告警
+func (m *SyslogMonitor) Evaluate(now time.Time) error {
+	m.mu.Lock()
+	checks := make([]thresholdCheck, 0, len(m.matchers))
+	for _, matcher := range m.matchers {
+		checks = append(checks, m.ruleCheck(matcher.rule, now))
+	}
+	m.mu.Unlock()
+
+	return m.update(checks, now)
+}
+
+// update 按检查结果发送告警或恢复通知
+func (m *SyslogMonitor) update(checks []thresholdCheck, now time.Time) error {
+	var errs []string
+	for _, check := range checks {
+		if err := m.notifier.Update(check, now); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// ruleCheck 统计规则在时间窗口内的匹配次数
+func (m *SyslogMonitor) ruleCheck(r SyslogRule, now time.Time) thresholdCheck {
+	since := now.Add(-time.Duration(r.Window) * time.Second)
+	matches := m.matches[r.ID]
+	for len(matches) > 0 && matches[0].Time.Before(since) {
+		matches = matches[1:]
+	}
+	m.matches[r.ID] = matches
+
+	check := thresholdCheck{
+		Key:   strconv.Itoa(r.ID),
+		Name:  "Syslog规则" + r.Name,
+		Value: fmt.Sprintf("%d秒内匹配%d次", r.Window, len(matches)),
+	}
+	if len(matches) < r.Threshold {
+		return check
+	}
+
+	check.Severity = r.Severity
+	if check.Severity == "" {
+		check.Severity = SeverityWarning
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s在%d秒内匹配%d次，达到%d次\n最近匹配的消息:", check.Name, r.Window, len(matches), r.Threshold)
+	for _, match := range matches[max(len(matches)-syslogContextLines, 0):] {
+		b.WriteString("\n  " + match.Line)
+	}
+	check.Breach = b.String()
+	return check
+}
@@ -0,0 +1,158 @@
+package util
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// syslogMaxFrameLen 单条syslog消息的最大长度
+const syslogMaxFrameLen = 64 << 10
+
+// SyslogServer 接收UDP和TCP的syslog消息
+type SyslogServer struct {
+	Handle func(msg SyslogMessage) // 消息处理函数，可能被多个连接并发调用
+
+	mu      sync.Mutex
+	closers map[io.Closer]bool
+	closed  bool
+}
+
+// NewSyslogServer 创建syslog服务
+func NewSyslogServer(handle func(msg SyslogMessage)) *SyslogServer {
+	return &SyslogServer{Handle: handle, closers: make(map[io.Closer]bool)}
+}
+
+// Listen 按协议在指定地址监听
+func (s *SyslogServer) Listen(protocol, address string) error {
+	if protocol == SyslogProtocolUDP || protocol == SyslogProtocolBoth {
+		conn, err := net.ListenPacket("udp", address)
+		if err != nil {
+			return fmt.Errorf("监听UDP %s失败: %v", address, err)
+		}
+		if !s.track(conn) {
+			return nil
+		}
+		go s.serveUDP(conn)
+	}
+	if protocol == SyslogProtocolTCP || protocol == SyslogProtocolBoth {
+		l, err := net.Listen("tcp", address)
+		if err != nil {
+			return fmt.Errorf("监听TCP %s失败: %v", address, err)
+		}
+		if !s.track(l) {
+			return nil
+		}
+		go s.serveTCP(l)
+	}
+	return nil
+}
+
+// Close 关闭所有监听和连接
+func (s *SyslogServer) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	for c := range s.closers {
+		c.Close()
+	}
+	s.closers = make(map[io.Closer]bool)
+}
+
+// track 记录需要关闭的监听或连接，服务已关闭时直接关闭并返回false
+func (s *SyslogServer) track(c io.Closer) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		c.Close()
+		return false
+	}
+	s.closers[c] = true
+	return true
+}
+
+func (s *SyslogServer) untrack(c io.Closer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.closers, c)
+	c.Close()
+}
+
+func (s *SyslogServer) serveUDP(conn net.PacketConn) {
+	defer s.untrack(conn)
+	buf := make([]byte, syslogMaxFrameLen)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		s.Handle(ParseSyslog(buf[:n], addr.String(), time.Now()))
+	}
+}
+
+func (s *SyslogServer) serveTCP(l net.Listener) {
+	defer s.untrack(l)
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		if !s.track(conn) {
+			return
+		}
+		go s.serveConn(conn)
+	}
+}
+
+// serveConn 读取TCP连接中的消息，支持RFC 6587的长度前缀和换行分隔两种分帧方式
+func (s *SyslogServer) serveConn(conn net.Conn) {
+	defer s.untrack(conn)
+	r := bufio.NewReaderSize(conn, syslogMaxFrameLen)
+	remote := conn.RemoteAddr().String()
+	for {
+		frame, err := readSyslogFrame(r)
+		if len(frame) > 0 {
+			s.Handle(ParseSyslog(frame, remote, time.Now()))
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func readSyslogFrame(r *bufio.Reader) ([]byte, error) {
+	first, err := r.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+
+	// 长度前缀: MSG-LEN SP SYSLOG-MSG
+	if first[0] >= '1' && first[0] <= '9' {
+		prefix, err := r.ReadString(' ')
+		if err != nil {
+			return nil, err
+		}
+		n, err := strconv.Atoi(prefix[:len(prefix)-1])
+		if err != nil || n > syslogMaxFrameLen {
+			return nil, fmt.Errorf("消息长度错误: %s", prefix)
+		}
+		frame := make([]byte, n)
+		_, err = io.ReadFull(r, frame)
+		return frame, err
+	}
+
+	line, err := r.ReadSlice('\n')
+	if err == bufio.ErrBufferFull {
+		// 超长的行截断处理，丢弃剩余部分
+		frame := append([]byte(nil), line...)
+		for err == bufio.ErrBufferFull {
+			_, err = r.ReadSlice('\n')
+		}
+		return frame, err
+	}
+	return append([]byte(nil), line...), err
+}
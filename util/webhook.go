@@ -0,0 +1,96 @@
+package util
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// DefaultWebhookBody 默认的Webhook请求体模板
+const DefaultWebhookBody = `{"system_name":{{json .SystemName}},"source":{{json .Source}},"title":{{json .Title}},"severity":{{json .Severity}},"message":{{json .Message}},"timestamp":{{.Timestamp}}}`
+
+// WebhookConfig Webhook配置结构
+type WebhookConfig struct {
+	URL                string            `json:"url"`
+	Method             string            `json:"method"`               // 请求方法，默认POST
+	Headers            map[string]string `json:"headers"`              // 请求头
+	Body               string            `json:"body"`                 // 请求体模板(text/template)，为空时使用默认JSON模板
+	Timeout            int               `json:"timeout"`              // 超时时间(秒)
+	InsecureSkipVerify bool              `json:"insecure_skip_verify"` // 是否跳过TLS证书校验
+}
+
+// webhookFuncs 请求体模板可用的函数
+var webhookFuncs = template.FuncMap{
+	// json 将值编码为JSON，用于在JSON模板中安全地输出字符串
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	// time 按指定格式格式化时间戳
+	"time": func(layout string, ts int64) string {
+		return time.Unix(ts, 0).Format(layout)
+	},
+}
+
+// ParseWebhookTemplate 解析Webhook请求体模板
+func ParseWebhookTemplate(body string) (*template.Template, error) {
+	if body == "" {
+		body = DefaultWebhookBody
+	}
+	tmpl, err := template.New("webhook").Funcs(webhookFuncs).Parse(body)
+	if err != nil {
+		return nil, fmt.Errorf("解析请求体模板失败: %v", err)
+	}
+	return tmpl, nil
+}
+
+// ValidateWebhookConfig 校验Webhook配置
+func ValidateWebhookConfig(config WebhookConfig) error {
+	if !strings.HasPrefix(config.URL, "http://") && !strings.HasPrefix(config.URL, "https://") {
+		return fmt.Errorf("Webhook地址必须以http://或https://开头")
+	}
+	_, err := ParseWebhookTemplate(config.Body)
+	return err
+}
+
+// SendWebhook 按配置渲染请求体并发送Webhook
+func SendWebhook(ctx context.Context, config WebhookConfig, alert Alert) error {
+	tmpl, err := ParseWebhookTemplate(config.Body)
+	if err != nil {
+		return err
+	}
+
+	var body bytes.Buffer
+	if err = tmpl.Execute(&body, alert); err != nil {
+		return fmt.Errorf("渲染请求体失败: %v", err)
+	}
+
+	method := strings.ToUpper(config.Method)
+	if method == "" {
+		method = http.MethodPost
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, config.URL, &body)
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range config.Headers {
+		req.Header.Set(key, value)
+	}
+
+	_, err = DoHTTPRequest(NewHTTPClient(config.Timeout, config.InsecureSkipVerify), req)
+	return err
+}
+
+// SendTestWebhook 发送测试Webhook
+func SendTestWebhook(ctx context.Context, config WebhookConfig, systemName string) error {
+	alert := NewAlert("test", "测试消息", SeverityInfo, "这是一条测试消息，用于验证Webhook配置是否正确。")
+	alert.SystemName = systemName
+	return SendWebhook(ctx, config, alert)
+}